@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// Number of objects that are requested from the api server per page
+	listPageSize = 500
+	// Number of namespaces whose tasks are listed at the same time
+	catalogWorkers = 8
+)
+
+// Tasks of a namespace, indexed by their names
+type namespaceTasks struct {
+	tasks   []extendedTask
+	byName  map[string]*extendedTask
+	invalid []invalidObject
+	err     error
+}
+
+// Catalog of the tasks in the cluster, keyed by namespace. It is built once and shared by the validations
+// of all the pipelines so that every namespace is listed only once, no matter how many pipelines it has.
+type taskCatalog map[string]*namespaceTasks
+
+// Lists the tasks of the given namespaces concurrently and returns them as a catalog. A namespace whose
+// tasks can not be listed is kept in the catalog with its error.
+func buildTaskCatalog(ctx context.Context, client dynamic.Interface, namespaces []string) taskCatalog {
+	catalog := make(taskCatalog)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, catalogWorkers)
+	for _, ns := range uniqueStrings(namespaces) {
+		wg.Add(1)
+		go func(ns string) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			entry := &namespaceTasks{}
+			list, err := listAll(ctx, client.Resource(tektonResource("tasks")).Namespace(ns), v1.ListOptions{})
+			if err != nil {
+				entry.err = fmt.Errorf("listing tasks in %s: %w", ns, err)
+			} else {
+				entry.tasks, entry.invalid = convertTasks(list)
+				entry.byName = make(map[string]*extendedTask, len(entry.tasks))
+				for i := range entry.tasks {
+					entry.byName[entry.tasks[i].getName()] = &entry.tasks[i]
+				}
+			}
+			mu.Lock()
+			catalog[ns] = entry
+			mu.Unlock()
+		}(ns)
+	}
+	wg.Wait()
+	return catalog
+}
+
+// Returns the tasks of the namespace, or the error that was returned when they were listed
+func (c taskCatalog) tasks(ns string) ([]extendedTask, error) {
+	entry, ok := c[ns]
+	if !ok || entry == nil {
+		return nil, fmt.Errorf("the tasks in %s are not in the catalog", ns)
+	}
+	return entry.tasks, entry.err
+}
+
+// Returns the task with the given name in the namespace or nil if there is no such task
+func (c taskCatalog) task(ns, name string) *extendedTask {
+	if entry := c[ns]; entry != nil {
+		return entry.byName[name]
+	}
+	return nil
+}
+
+// Returns the tasks that could not be converted, ordered by namespace
+func (c taskCatalog) invalid() (invalid []invalidObject) {
+	var namespaces []string
+	for ns := range c {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		if entry := c[ns]; entry != nil {
+			invalid = append(invalid, entry.invalid...)
+		}
+	}
+	return
+}
+
+// Lists all the objects of the resource, following ListOptions.Continue through the pages of at most listPageSize objects
+func listAll(ctx context.Context, ri dynamic.ResourceInterface, opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
+	all := &unstructured.UnstructuredList{}
+	opts.Limit = listPageSize
+	for {
+		page, err := ri.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		all.Object = page.Object
+		all.Items = append(all.Items, page.Items...)
+		if page.GetContinue() == "" {
+			return all, nil
+		}
+		opts.Continue = page.GetContinue()
+	}
+}
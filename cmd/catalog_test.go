@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Resource which serves its items in pages of the given size
+type pagedResource struct {
+	dynamic.ResourceInterface
+	items    []unstructured.Unstructured
+	pageSize int
+	requests []v1.ListOptions
+}
+
+func (r *pagedResource) List(ctx context.Context, opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.requests = append(r.requests, opts)
+	start := 0
+	if opts.Continue != "" {
+		start, _ = strconv.Atoi(opts.Continue)
+	}
+	end := start + r.pageSize
+	page := &unstructured.UnstructuredList{}
+	if end < len(r.items) {
+		page.SetContinue(strconv.Itoa(end))
+	} else {
+		end = len(r.items)
+	}
+	page.Items = r.items[start:end]
+	return page, nil
+}
+
+func TestListAll(t *testing.T) {
+	r := &pagedResource{pageSize: 2}
+	for i := 0; i < 5; i++ {
+		u := testObject(t, yTaskFinally, "team-a")
+		u.SetName("task-" + strconv.Itoa(i))
+		r.items = append(r.items, *u)
+	}
+
+	list, err := listAll(context.TODO(), r, v1.ListOptions{LabelSelector: "team=a"})
+	if err != nil {
+		t.Fatalf("did not expect error but got: %s", err)
+	}
+	if len(list.Items) != 5 || list.Items[4].GetName() != "task-4" {
+		t.Errorf("got %d items but wanted the 5 tasks", len(list.Items))
+	}
+	if len(r.requests) != 3 {
+		t.Fatalf("got %d requests but wanted 3 pages", len(r.requests))
+	}
+	for i, opts := range r.requests {
+		if opts.Limit != listPageSize || opts.LabelSelector != "team=a" {
+			t.Errorf("request %d did not keep the options: %#v", i, opts)
+		}
+	}
+	if r.requests[2].Continue != "4" {
+		t.Errorf("got continue %q but wanted the token of the last page", r.requests[2].Continue)
+	}
+}
+
+// every namespace must be listed once, no matter how many pipelines it has
+func TestBuildTaskCatalog(t *testing.T) {
+	unconvertible := testObject(t, yTaskFinally, "team-a")
+	unconvertible.SetName("unconvertible-task")
+	unconvertible.Object["spec"] = "not-a-spec"
+	client := teamClient(t,
+		testObject(t, yTaskFinally, "team-a"),
+		testObject(t, yTaskFinally, "team-b"),
+		unconvertible,
+	)
+	var mu sync.Mutex
+	lists := make(map[string]int)
+	client.PrependReactor("list", "tasks", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		lists[action.GetNamespace()]++
+		return false, nil, nil
+	})
+
+	catalog := buildTaskCatalog(context.TODO(), client, []string{"team-a", "team-a", "team-b", "team-a"})
+
+	if lists["team-a"] != 1 || lists["team-b"] != 1 {
+		t.Errorf("got %v lists but wanted every namespace to be listed once", lists)
+	}
+	tasks, err := catalog.tasks("team-a")
+	if err != nil || len(tasks) != 1 {
+		t.Errorf("got %d tasks and %v but wanted task-finally in team-a", len(tasks), err)
+	}
+	if catalog.task("team-a", "task-finally") == nil || catalog.task("team-a", "task-a") != nil {
+		t.Error("wanted task-finally to be indexed in team-a")
+	}
+	if _, err := catalog.tasks("team-b"); err == nil {
+		t.Error("wanted the forbidden tasks of team-b to be reported")
+	}
+	if _, err := catalog.tasks("team-c"); err == nil {
+		t.Error("wanted an error for a namespace which is not in the catalog")
+	}
+	if invalid := catalog.invalid(); len(invalid) != 1 || invalid[0].name != "unconvertible-task" {
+		t.Errorf("got %v but wanted unconvertible-task to be invalid", invalid)
+	}
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"sort"
+)
+
+// The severities of the diagnostics
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// The rule codes of the validations. They are stable identifiers which are used by the machine readable outputs.
+const (
+	ruleTaskRef          = "task-ref"
+	ruleTaskKind         = "task-kind"
+	ruleParams           = "params"
+	ruleWorkspaces       = "workspaces"
+	ruleUndeclaredParams = "undeclared-params"
+	ruleParamTypes       = "param-types"
+	ruleResultRefs       = "result-refs"
+	ruleRunAfter         = "run-after"
+	ruleGraph            = "graph"
+	ruleParamRefs        = "param-refs"
+	ruleUnusedParams     = "unused-params"
+	ruleUnusedWorkspaces = "unused-workspaces"
+	ruleUnreadable       = "unreadable"
+
+	// pipelineRuns
+	rulePipelineRef     = "pipeline-ref"
+	ruleRunParams       = "run-params"
+	ruleRunWorkspaces   = "run-workspaces"
+	ruleTaskRunSpecs    = "task-run-specs"
+	ruleServiceAccounts = "service-accounts"
+
+	// tasks
+	ruleTaskParams     = "task-params"
+	ruleTaskWorkspaces = "task-workspaces"
+	ruleTaskResults    = "task-results"
+	ruleStepNames      = "step-names"
+
+	// tekton triggers
+	ruleTemplateParams = "template-params"
+	ruleTriggerRefs    = "trigger-refs"
+	ruleBindingParams  = "binding-params"
+)
+
+// The metadata of a rule, which the reports describe. name is its human readable name and severity is the
+// default severity of its diagnostics.
+type ruleInfo struct {
+	id          string
+	name        string
+	description string
+	severity    string
+}
+
+// The rules of all the kinds of objects, in the order that the reports list them
+var registeredRules = []ruleInfo{
+	{ruleTaskRef, "taskRef validation", "Tasks and clusterTasks that are referred by pipelineTasks must exist", severityError},
+	{ruleTaskKind, "taskRef kind validation", "taskRef.kind must match the kind of the task that exists with that name", severityError},
+	{ruleParams, "parameter validation", "Task params without a default value must be supplied by the pipelineTask", severityError},
+	{ruleWorkspaces, "workspace validation", "Task workspaces that are not optional must be bound to a workspace of the pipeline", severityError},
+	{ruleUndeclaredParams, "undeclared parameter validation", "Params that pipelineTasks pass must be declared by the task", severityError},
+	{ruleParamTypes, "parameter type validation", "Param values, references and defaults must match the declared param types", severityError},
+	{ruleResultRefs, "result reference validation", "Task results that are referenced must be declared by the tasks", severityError},
+	{ruleRunAfter, "runAfter validation", "runAfter must refer to pipelineTasks in spec.tasks", severityError},
+	{ruleGraph, "dependency graph validation", "pipelineTasks must have unique names and must not depend on each other in a cycle", severityError},
+	{ruleParamRefs, "parameter reference validation", "Params that pipelineTasks reference must be declared in spec.params", severityError},
+	{ruleUnusedParams, "unused params validation", "Params in spec.params should be used by a pipelineTask", severityWarning},
+	{ruleUnusedWorkspaces, "unused workspaces validation", "Workspaces in spec.workspaces should be used by a pipelineTask", severityWarning},
+	{ruleUnreadable, "readability validation", "Objects and the objects they refer to must be readable from the files and the cluster", severityError},
+
+	{rulePipelineRef, "pipeline reference validation", "pipelineRuns must refer to a pipeline that exists or embed a pipelineSpec", severityError},
+	{ruleRunParams, "pipelineRun params validation", "pipelineRuns must supply the params of the pipeline that do not have a default", severityError},
+	{ruleRunWorkspaces, "pipelineRun workspaces validation", "pipelineRuns must bind the workspaces of the pipeline that are not optional", severityError},
+	{ruleTaskRunSpecs, "taskRunSpecs validation", "taskRunSpecs must refer to the pipelineTasks of the pipeline", severityError},
+	{ruleServiceAccounts, "service accounts validation", "serviceAccountName and the taskServiceAccountName of taskRunSpecs must exist in the namespace", severityError},
+
+	{ruleTaskParams, "task params validation", "$(params.x) references must be declared in spec.params of the task and its params should be used", severityError},
+	{ruleTaskWorkspaces, "task workspaces validation", "Workspaces that steps use must be declared in spec.workspaces of the task and its workspaces should be used", severityError},
+	{ruleTaskResults, "task results validation", "$(results.x.path) references must be declared in spec.results of the task and its results should be written", severityError},
+	{ruleStepNames, "step names validation", "Steps of a task must have unique names", severityError},
+
+	{ruleTemplateParams, "TriggerTemplate params validation", "$(tt.params.x) references must refer to the params of the TriggerTemplate and its params must be used", severityError},
+	{ruleTriggerRefs, "trigger references validation", "triggers must refer to TriggerTemplates, TriggerBindings and Triggers that exist", severityError},
+	{ruleBindingParams, "binding params validation", "the bindings of a trigger must supply the params of its TriggerTemplate that do not have a default", severityError},
+}
+
+// Returns the registered rule with the given id, or a rule which is named after the id if there is no such rule
+func lookupRule(id string) ruleInfo {
+	for _, r := range registeredRules {
+		if r.id == id {
+			return r
+		}
+	}
+	return ruleInfo{id: id, name: id, severity: severityError}
+}
+
+// Where a diagnostic is. pipelineTask is empty for the problems that are not specific to a pipelineTask,
+// in which case field points to the part of the pipeline, e.g. spec.params. file, line and column are
+// only known for the pipelines that are read from files.
+type location struct {
+	pipelineTask string
+	field        string
+	file         string
+	line         int
+	column       int
+}
+
+// A single problem that a validation found. subject is the name of the offending param, workspace, task, etc.
+// and suggestion is an optional hint on how to fix the problem, e.g. did you mean param1?
+type diagnostic struct {
+	rule       string
+	severity   string
+	location   location
+	subject    string
+	message    string
+	suggestion string
+}
+
+// Returns the pipelineTask of the location, or the field if the location is not specific to a pipelineTask
+func (l location) key() string {
+	if l.pipelineTask != "" {
+		return l.pipelineTask
+	}
+	return l.field
+}
+
+// Returns the message of the diagnostic followed by its suggestion
+func (d diagnostic) text() string {
+	if d.suggestion == "" {
+		return d.message
+	}
+	return d.message + ", " + d.suggestion
+}
+
+// Returns the diagnostic as pipelineTask (or field): message
+func (d diagnostic) String() string {
+	return d.location.key() + ": " + d.text()
+}
+
+// Sorts the diagnostics by their position in the file, pipelineTask (or field), rule, subject and message
+// so that the output does not depend on the order in which they are found. It returns the sorted diagnostics.
+func sortDiagnostics(diags []diagnostic) []diagnostic {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		switch {
+		case a.location.file != b.location.file:
+			return a.location.file < b.location.file
+		case a.location.line != b.location.line:
+			return a.location.line < b.location.line
+		case a.location.column != b.location.column:
+			return a.location.column < b.location.column
+		case a.location.key() != b.location.key():
+			return a.location.key() < b.location.key()
+		case a.rule != b.rule:
+			return a.rule < b.rule
+		case a.subject != b.subject:
+			return a.subject < b.subject
+		}
+		return a.message < b.message
+	})
+	return diags
+}
+
+// Returns true if the diagnostics already include d
+func includesDiagnostic(diags []diagnostic, d diagnostic) bool {
+	for _, v := range diags {
+		if v == d {
+			return true
+		}
+	}
+	return false
+}
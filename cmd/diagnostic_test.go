@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// diagnostics must be sorted by position, then by pipelineTask (or field), rule, subject and message
+func TestSortDiagnostics(t *testing.T) {
+	diags := []diagnostic{
+		{rule: ruleParams, location: location{pipelineTask: "task-b"}, subject: "param1", message: "b"},
+		{rule: ruleWorkspaces, location: location{file: "a.yaml", line: 20, column: 7}, message: "c"},
+		{rule: ruleUnusedParams, location: location{field: "spec.params"}, subject: "param1", message: "d"},
+		{rule: ruleTaskRef, location: location{file: "a.yaml", line: 10, column: 7}, message: "e"},
+		{rule: ruleGraph, location: location{pipelineTask: "task-b"}, subject: "task-b", message: "f"},
+		{rule: ruleParams, location: location{pipelineTask: "task-a"}, subject: "param2", message: "g"},
+	}
+	var got string
+	for _, d := range sortDiagnostics(diags) {
+		got += d.message
+	}
+	if want := "dgfbec"; got != want {
+		t.Errorf("got diagnostics in the order %s but wanted %s", got, want)
+	}
+}
+
+// runValidations must report every diagnostic with the rule and severity of its validation
+func TestRunValidations(t *testing.T) {
+	tPipeline := testPipeline(t, yPipeline)
+	severities := make(map[string]string)
+	for _, v := range pipelineValidations {
+		severities[v.rule] = lookupRule(v.rule).severity
+	}
+	diags := runValidations(&tPipeline, nil, nil, nil)
+	if len(diags) == 0 {
+		t.Fatal("wanted diagnostics but did not get any")
+	}
+	for _, d := range diags {
+		if severity, ok := severities[d.rule]; !ok || severity != d.severity {
+			t.Errorf("got rule %q with severity %q which does not match any validation", d.rule, d.severity)
+		}
+	}
+}
+
+// every rule that the objects are checked against must be registered once, so that the reports can describe it
+func TestRegisteredRules(t *testing.T) {
+	registered := make(map[string]int)
+	for _, r := range registeredRules {
+		registered[r.id]++
+	}
+	rules := append(append([]string{ruleUnreadable}, templateRules...), triggerRules...)
+	rules = append(rules, pipelineRunRules()...)
+	for _, v := range pipelineValidations {
+		rules = append(rules, v.rule)
+	}
+	for _, v := range taskValidations {
+		rules = append(rules, v.rule)
+	}
+	for _, rule := range rules {
+		if registered[rule] != 1 {
+			t.Errorf("the rule %s is registered %d times but wanted once", rule, registered[rule])
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The exit codes of mario
+const (
+	exitOK       = 0
+	exitFindings = 1 // the validations reported diagnostics at or above --fail-on
+	exitUsage    = 2 // the flags, the input files or the kubeconfig are not valid
+	exitCluster  = 3 // the cluster can not be reached or its objects can not be listed
+	exitRuntime  = 4 // any other error of the validation, e.g. the results can not be printed
+)
+
+// The supported values of --fail-on
+const (
+	failOnError   = "error"
+	failOnWarning = "warning"
+	failOnNone    = "none"
+)
+
+var failOnValues = []string{failOnError, failOnWarning, failOnNone}
+
+// An error which carries the code that mario exits with. Errors without err are
+// not printed, e.g. the diagnostics are already printed when the validations fail.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// Returns an error which makes mario exit with exitUsage
+func usageError(err error) error {
+	return &exitError{code: exitUsage, err: err}
+}
+
+// Returns an error which makes mario exit with exitCluster
+func clusterError(err error) error {
+	return &exitError{code: exitCluster, err: err}
+}
+
+// Returns an error which makes mario exit with exitRuntime, unless the error already carries a code
+func runtimeError(err error) error {
+	var eErr *exitError
+	if err == nil || errors.As(err, &eErr) {
+		return err
+	}
+	return &exitError{code: exitRuntime, err: err}
+}
+
+// Returns the code that mario exits with for the error that a command returns. Errors that do not
+// carry a code are usage errors, e.g. unknown flags, the errors of the validation carry one through
+// validateRunE.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var eErr *exitError
+	if errors.As(err, &eErr) {
+		return eErr.code
+	}
+	return exitUsage
+}
+
+// Returns true if any of the results has a diagnostic at or above the given --fail-on threshold
+func failsOn(results []pipelineResult, threshold string) bool {
+	for _, r := range results {
+		for _, d := range r.diagnostics {
+			if meetsThreshold(d.severity, threshold) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns true if the severity is at or above the given --fail-on threshold
+func meetsThreshold(severity, threshold string) bool {
+	switch threshold {
+	case failOnError:
+		return severity == severityError
+	case failOnWarning:
+		return severity == severityError || severity == severityWarning
+	}
+	return false
+}
+
+// Returns the error that the validate command returns after the results are printed
+func resultsError(results []pipelineResult) error {
+	if failsOn(results, failOn) {
+		return &exitError{code: exitFindings}
+	}
+	return nil
+}
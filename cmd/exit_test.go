@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "no error", err: nil, want: exitOK},
+		{name: "findings", err: &exitError{code: exitFindings}, want: exitFindings},
+		{name: "usage error", err: usageError(errors.New("unknown output format")), want: exitUsage},
+		{name: "wrapped cluster error", err: fmt.Errorf("validate: %w", clusterError(errors.New("connection refused"))), want: exitCluster},
+		{name: "error without a code", err: errors.New("unknown flag: --foo"), want: exitUsage},
+		{name: "runtime error", err: runtimeError(errors.New("printing the results")), want: exitRuntime},
+		{name: "runtime error of an error with a code", err: runtimeError(clusterError(errors.New("connection refused"))), want: exitCluster},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := exitCode(tc.err); got != tc.want {
+				t.Errorf("got exit code %d but wanted %d", got, tc.want)
+			}
+		})
+	}
+}
+
+// --fail-on decides the lowest severity that fails the validation
+func TestFailsOn(t *testing.T) {
+	warnings := []pipelineResult{{diagnostics: []diagnostic{{rule: ruleUnusedParams, severity: severityWarning}}}}
+	errs := []pipelineResult{{diagnostics: []diagnostic{{rule: ruleParams, severity: severityError}}}, {}}
+
+	testCases := []struct {
+		threshold string
+		results   []pipelineResult
+		want      bool
+	}{
+		{threshold: failOnError, results: nil, want: false},
+		{threshold: failOnError, results: warnings, want: false},
+		{threshold: failOnError, results: errs, want: true},
+		{threshold: failOnWarning, results: warnings, want: true},
+		{threshold: failOnWarning, results: errs, want: true},
+		{threshold: failOnNone, results: errs, want: false},
+	}
+	for _, tc := range testCases {
+		if got := failsOn(tc.results, tc.threshold); got != tc.want {
+			t.Errorf("failsOn(%v, %s) is %t but wanted %t", tc.results, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+// validating local manifests must exit with exitFindings for diagnostics and exitUsage for unreadable tasks
+func TestValidateOfflineExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pipeline.yaml"), yPipeline)
+	writeFile(t, filepath.Join(dir, "tasks.yaml"), yTasks)
+	set, err := loadManifests([]string{filepath.Join(dir, "pipeline.yaml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(files []string, threshold string) {
+		tasksFiles, failOn = files, threshold
+	}(tasksFiles, failOn)
+
+	tasksFiles = []string{filepath.Join(dir, "tasks.yaml")}
+	failOn = failOnError
+	if got := exitCode(validateOffline(io.Discard, set)); got != exitFindings {
+		t.Errorf("got exit code %d but wanted %d", got, exitFindings)
+	}
+	failOn = failOnNone
+	if got := exitCode(validateOffline(io.Discard, set)); got != exitOK {
+		t.Errorf("got exit code %d with --fail-on=none but wanted %d", got, exitOK)
+	}
+
+	tasksFiles = []string{filepath.Join(dir, "missing.yaml")}
+	if got := exitCode(validateOffline(io.Discard, set)); got != exitUsage {
+		t.Errorf("got exit code %d for a missing tasks file but wanted %d", got, exitUsage)
+	}
+}
+
+// the usage must only be printed for the errors of the flag checks, not for the errors of the validation
+func TestValidateRunE(t *testing.T) {
+	failing := func(cmd *cobra.Command, args []string) error { return usageError(errors.New("bad flag")) }
+	passing := func(cmd *cobra.Command, args []string) error { return nil }
+	unreachable := func(cmd *cobra.Command, args []string) error { return clusterError(errors.New("connection refused")) }
+	broken := func(cmd *cobra.Command, args []string) error { return errors.New("printing the results: broken pipe") }
+
+	testCases := []struct {
+		name         string
+		check, run   func(cmd *cobra.Command, args []string) error
+		want         int
+		silenceUsage bool
+	}{
+		{name: "check fails", check: failing, run: passing, want: exitUsage, silenceUsage: false},
+		{name: "run fails", check: passing, run: unreachable, want: exitCluster, silenceUsage: true},
+		{name: "run fails without a code", check: passing, run: broken, want: exitRuntime, silenceUsage: true},
+		{name: "without check", run: passing, want: exitOK, silenceUsage: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			err := validateRunE(tc.check, tc.run)(cmd, nil)
+			if got := exitCode(err); got != tc.want {
+				t.Errorf("got exit code %d but wanted %d", got, tc.want)
+			}
+			if cmd.SilenceUsage != tc.silenceUsage {
+				t.Errorf("got SilenceUsage %t but wanted %t", cmd.SilenceUsage, tc.silenceUsage)
+			}
+		})
+	}
+}
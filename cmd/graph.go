@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dependency graph of a pipeline. Every pipelineTask maps to the pipelineTasks that it depends on
+// through runAfter, $(tasks.x.results.y) references or the from clauses of its input resources.
+type pipelineGraph map[string][]string
+
+// Builds the dependency graph of the pipelineTasks in spec.tasks and spec.finally
+func buildGraph(p *extendedPipeline) pipelineGraph {
+	g := make(pipelineGraph)
+	for _, pt := range allPipelineTasks(p) {
+		deps := append([]string{}, pt.RunAfter...)
+		for _, v := range stringValues([]interface{}{pt.Params, pt.Matrix, pt.WhenExpressions}) {
+			for _, ref := range resultReferences(v) {
+				deps = append(deps, ref.pipelineTask)
+			}
+		}
+		if pt.Resources != nil {
+			for _, input := range pt.Resources.Inputs {
+				deps = append(deps, input.From...)
+			}
+		}
+		g[pt.Name] = append(g[pt.Name], uniqueStrings(deps)...)
+	}
+	return g
+}
+
+// Returns the first cycle in the graph as a path which starts and ends with the same pipelineTask,
+// e.g. [task-a task-b task-a]. Self dependencies are not considered to be cycles. It returns nil
+// if the graph does not have any cycle.
+func (g pipelineGraph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(n string) []string
+	visit = func(n string) []string {
+		state[n] = visiting
+		path = append(path, n)
+		for _, d := range g[n] {
+			if _, ok := g[d]; !ok || d == n {
+				continue
+			}
+			switch state[d] {
+			case visiting:
+				for i, v := range path {
+					if v == d {
+						return append(append([]string{}, path[i:]...), d)
+					}
+				}
+			case unvisited:
+				if cycle := visit(d); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = visited
+		return nil
+	}
+
+	for _, n := range g.sortedNodes() {
+		if state[n] == unvisited {
+			if cycle := visit(n); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the names of the pipelineTasks in the graph in alphabetical order
+func (g pipelineGraph) sortedNodes() (nodes []string) {
+	for n := range g {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	return
+}
+
+// Ensures that the dependency graph of the pipeline is a valid DAG. It reports the pipelineTask names that are
+// used more than once across spec.tasks and spec.finally, pipelineTasks that depend on themselves and cycles.
+func (p *extendedPipeline) ValidateGraph() []diagnostic {
+	var diags []diagnostic
+
+	seen := make(map[string]int)
+	for _, pt := range allPipelineTasks(p) {
+		seen[pt.Name]++
+	}
+	for name, count := range seen {
+		if count > 1 {
+			diags = append(diags, graphDiagnostic(name, fmt.Sprintf("the name %s is used by %d pipelineTasks", name, count)))
+		}
+	}
+
+	g := buildGraph(p)
+	for _, n := range g.sortedNodes() {
+		if sliceIncludeString(g[n], n) {
+			diags = append(diags, graphDiagnostic(n, fmt.Sprintf("%s depends on itself", n)))
+		}
+	}
+	if cycle := g.findCycle(); cycle != nil {
+		diags = append(diags, graphDiagnostic(cycle[0], fmt.Sprintf("dependency cycle: %s", strings.Join(cycle, " -> "))))
+	}
+	return sortDiagnostics(diags)
+}
+
+// Returns a graph diagnostic about the given pipelineTask
+func graphDiagnostic(pipelineTask, message string) diagnostic {
+	return diagnostic{rule: ruleGraph, severity: severityError, location: location{pipelineTask: pipelineTask}, subject: pipelineTask, message: message}
+}
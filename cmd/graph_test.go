@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"testing"
+)
+
+var yCyclicPipeline = `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: cyclic-pipeline
+spec:
+  tasks:
+    - name: task-a
+      taskRef:
+        name: task-a
+      runAfter:
+        - task-c
+    - name: task-b
+      taskRef:
+        name: task-b
+      params:
+        - name: digest
+          value: $(tasks.task-a.results.digest)
+    - name: task-c
+      taskRef:
+        name: task-c
+      resources:
+        inputs:
+          - name: source
+            resource: source
+            from:
+              - task-b
+    - name: task-d
+      taskRef:
+        name: task-d
+      runAfter:
+        - task-d
+  finally:
+    - name: task-a
+      taskRef:
+        name: task-a
+`
+
+// pipelineTasks must have unique names and must not have cycles or depend on themselves
+func TestValidateGraph(t *testing.T) {
+	validateGraphTests := []ValidatePipelineTestCases{
+		{
+			name:     "test pipeline is a valid DAG",
+			pipeline: yPipeline,
+			want:     nil,
+		},
+		{
+			name:     "pipeline has a cycle, a self dependency and a duplicate name",
+			pipeline: yCyclicPipeline,
+			want: []string{
+				"task-a: dependency cycle: task-a -> task-c -> task-b -> task-a",
+				"task-a: the name task-a is used by 2 pipelineTasks",
+				"task-d: task-d depends on itself",
+			},
+		},
+	}
+
+	for _, tc := range validateGraphTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tPipeline := testPipeline(t, tc.pipeline)
+			got := tPipeline.ValidateGraph()
+			assertion(t, got, tc.want)
+		})
+	}
+}
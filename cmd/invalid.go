@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// An object that can not be read from a file or converted into its tekton type. It is reported as
+// a diagnostic instead of stopping the validation of the other objects.
+type invalidObject struct {
+	kind      string
+	name      string
+	namespace string
+	location  location
+	err       error
+}
+
+// Returns the invalid object for an unstructured object that can not be converted into its tekton type
+func newInvalidObject(u *unstructured.Unstructured, err error) invalidObject {
+	return invalidObject{
+		kind:      u.GetKind(),
+		name:      u.GetName(),
+		namespace: u.GetNamespace(),
+		err:       fmt.Errorf("converting %s %s: %w", u.GetKind(), u.GetName(), err),
+	}
+}
+
+// Returns the invalid object for a document that can not be parsed or decoded. It is named after its file.
+func invalidDocument(doc document, err error) invalidObject {
+	o := invalidObject{name: filepath.Base(doc.source.file), location: doc.source.rootLocation(), err: err}
+	if doc.line > 0 {
+		o.location.line, o.location.column = doc.line, 1
+	}
+	return o
+}
+
+// Returns the object of the document which can not be converted, located at the root of the document
+func invalidManifest(u *unstructured.Unstructured, doc document, err error) invalidObject {
+	o := newInvalidObject(u, err)
+	o.location = doc.source.rootLocation()
+	return o
+}
+
+// Returns the result that reports the invalid object as an unreadable diagnostic
+func (o invalidObject) result() pipelineResult {
+	d := diagnostic{rule: ruleUnreadable, severity: severityError, location: o.location, subject: o.name, message: o.err.Error()}
+	return pipelineResult{subject: resultSubject{kind: o.kind, name: o.name, namespace: o.namespace}, diagnostics: []diagnostic{d}}
+}
+
+// Returns the results that report the invalid objects. An object which is read more than once, e.g. from a
+// file that is given through --pipeline-file and --tasks-file, is only reported once.
+func invalidResults(objects []invalidObject) (results []pipelineResult) {
+	reported := make(map[string]bool)
+	for _, o := range objects {
+		key := fmt.Sprintf("%s/%s/%s/%s:%d", o.kind, o.namespace, o.name, o.location.file, o.location.line)
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+		results = append(results, o.result())
+	}
+	return
+}
+
+// Converts the items of a list into pipelines. The items that can not be converted are returned as invalid objects.
+func convertPipelines(list *unstructured.UnstructuredList) (ePipelines []extendedPipeline, invalid []invalidObject) {
+	for _, item := range list.Items {
+		eP, err := toPipeline(&item)
+		if err != nil {
+			invalid = append(invalid, newInvalidObject(&item, err))
+			continue
+		}
+		ePipelines = append(ePipelines, eP)
+	}
+	return
+}
+
+// Converts the items of a list into tasks. The items that can not be converted are returned as invalid objects.
+func convertTasks(list *unstructured.UnstructuredList) (eTasks []extendedTask, invalid []invalidObject) {
+	for _, item := range list.Items {
+		eT, err := toTask(&item)
+		if err != nil {
+			invalid = append(invalid, newInvalidObject(&item, err))
+			continue
+		}
+		eTasks = append(eTasks, eT)
+	}
+	return
+}
+
+// Converts the items of a list into clusterTasks. The items that can not be converted are returned as invalid objects.
+func convertClusterTasks(list *unstructured.UnstructuredList) (eClusterTasks []extendedClusterTask, invalid []invalidObject) {
+	for _, item := range list.Items {
+		var eCT extendedClusterTask
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &eCT); err != nil {
+			invalid = append(invalid, newInvalidObject(&item, err))
+			continue
+		}
+		eClusterTasks = append(eClusterTasks, eCT)
+	}
+	return
+}
+
+// Converts the items of a list into pipelineRuns. The items that can not be converted are returned as invalid objects.
+func convertPipelineRuns(list *unstructured.UnstructuredList) (ePipelineRuns []extendedPipelineRun, invalid []invalidObject) {
+	for _, item := range list.Items {
+		ePR, err := toPipelineRun(&item)
+		if err != nil {
+			invalid = append(invalid, newInvalidObject(&item, err))
+			continue
+		}
+		ePipelineRuns = append(ePipelineRuns, ePR)
+	}
+	return
+}
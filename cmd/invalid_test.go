@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var yUnconvertiblePipeline = `apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: unconvertible-pipeline
+spec:
+  tasks: not-a-list
+`
+
+// documents that can not be parsed or converted must be reported without dropping the other objects
+func TestSetupManifestsInvalidObjects(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pipelines.yaml"), yPipeline+"---\n"+yUnconvertiblePipeline)
+	writeFile(t, filepath.Join(dir, "broken.yaml"), yTaskFinally+"---\nkind: Task\nmetadata: [\n")
+
+	set, err := loadManifests([]string{filepath.Join(dir, "pipelines.yaml"), filepath.Join(dir, "broken.yaml")})
+	if err != nil {
+		t.Fatalf("did not expect error but got: %s", err)
+	}
+	if len(set.pipelines) != 1 || len(set.tasks) != 1 {
+		t.Errorf("got %d pipelines and %d tasks but wanted test-pipeline and task-finally", len(set.pipelines), len(set.tasks))
+	}
+	if len(set.invalid) != 2 {
+		t.Fatalf("got %d invalid objects but wanted 2: %v", len(set.invalid), set.invalid)
+	}
+
+	results := invalidResults(set.invalid)
+	pipeline, document := results[0].diagnostics[0], results[1].diagnostics[0]
+	if results[0].subject.name != "unconvertible-pipeline" || results[0].subject.kind != "Pipeline" {
+		t.Errorf("got %s %s but wanted Pipeline unconvertible-pipeline", results[0].subject.kind, results[0].subject.name)
+	}
+	if pipeline.rule != ruleUnreadable || pipeline.location.line != 75 || !strings.HasPrefix(pipeline.message, "converting Pipeline unconvertible-pipeline: ") {
+		t.Errorf("got %#v but wanted an unreadable diagnostic at line 75", pipeline)
+	}
+	if results[1].subject.name != "broken.yaml" || document.location.line != 7 || !strings.HasPrefix(document.message, "parsing ") {
+		t.Errorf("got %#v but wanted a parsing diagnostic for broken.yaml at line 7", document)
+	}
+}
+
+// a forbidden namespace or an unconvertible object must not stop the validation of the other pipelines
+func TestValidateClusterPartialResults(t *testing.T) {
+	scheme := runtime.NewScheme()
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}:    "PipelineList",
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}:        "TaskList",
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "clustertasks"}: "ClusterTaskList",
+	}
+	objects := []runtime.Object{
+		testObject(t, yPipeline, "team-a"),
+		testObject(t, yPipeline, "forbidden"),
+		testObject(t, yUnconvertiblePipeline, "team-a"),
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds, objects...)
+	client.PrependReactor("list", "tasks", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "forbidden" {
+			return true, nil, errors.New(`tasks.tekton.dev is forbidden`)
+		}
+		return false, nil, nil
+	})
+
+	defer func(files []string) { pipelineFiles = files }(pipelineFiles)
+	pipelineFiles = nil
+
+	var out strings.Builder
+	err := validateCluster(&out, client, manifestSet{}, "")
+	if got := exitCode(err); got != exitFindings {
+		t.Errorf("got exit code %d but wanted %d", got, exitFindings)
+	}
+	for _, want := range []string{
+		"team-a/test-pipeline: error: task-a refers to the task task-a which is not found [task-ref]",
+		"forbidden/test-pipeline: error: test-pipeline is not validated: listing tasks in forbidden: tasks.tekton.dev is forbidden [unreadable]",
+		"team-a/unconvertible-pipeline: error: converting Pipeline unconvertible-pipeline: ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("wanted %q in the output but got:\n%s", want, out.String())
+		}
+	}
+}
+
+// Decodes the yaml into an unstructured object in the given namespace
+func testObject(t *testing.T, y, namespace string) *unstructured.Unstructured {
+	t.Helper()
+	u, err := decodeDocument([]byte(y))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.SetNamespace(namespace)
+	return u
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The subset of the JUnit XML format that CI systems render
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+	junitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		TestCases []junitTestCase `xml:"testcase"`
+	}
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		SystemOut string        `xml:"system-out,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// Prints the results as a JUnit XML report. Every object is a testsuite and every rule that it was checked
+// against is a testcase of it, as well as the rules of the diagnostics that are found while the object is read,
+// e.g. unreadable. The rules whose diagnostics are at or above --fail-on fail with them, the diagnostics of the
+// others are printed to the system-out of their testcase.
+func printJUnit(w io.Writer, results []pipelineResult) error {
+	report := junitTestSuites{Name: "mario"}
+	for _, r := range results {
+		suite := junitTestSuite{Name: r.subject.id()}
+		rules := r.checks()
+		for _, d := range r.diagnostics {
+			if !sliceIncludeString(rules, d.rule) {
+				rules = append(rules, d.rule)
+			}
+		}
+		for _, rule := range rules {
+			tc := junitTestCase{Name: lookupRule(rule).name, ClassName: suite.Name}
+			var lines []string
+			severity := ""
+			for _, d := range r.diagnostics {
+				if d.rule == rule {
+					lines = append(lines, fmt.Sprintf("%s: %s: %s", r.where(d), d.severity, d.text()))
+					if severity != severityError {
+						severity = d.severity
+					}
+				}
+			}
+			switch {
+			case meetsThreshold(severity, failOn):
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%s found %d problem(s)", rule, len(lines)),
+					Type:    severity,
+					Text:    strings.Join(lines, "\n"),
+				}
+				suite.Failures++
+			case len(lines) > 0:
+				tc.SystemOut = strings.Join(lines, "\n")
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+			suite.Tests++
+		}
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// every pipeline must be a testsuite with a testcase per validation, including the ones that pass. Only the
+// validations with diagnostics at or above --fail-on fail, the diagnostics of the others go to system-out.
+func TestPrintJUnit(t *testing.T) {
+	tPipeline := testPipeline(t, yPipeline)
+	cTasks := []extendedTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
+	}
+	cClusterTasks := []extendedClusterTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+	}
+	result := newPipelineResult(&tPipeline, source{}, cTasks, cClusterTasks, nil)
+	lines := make(map[string][]string)
+	highest := make(map[string]string)
+	for _, d := range result.diagnostics {
+		lines[d.rule] = append(lines[d.rule], "test-pipeline: "+d.severity+": "+d.text())
+		if highest[d.rule] != severityError {
+			highest[d.rule] = d.severity
+		}
+	}
+	results := []pipelineResult{result}
+	defer func(threshold string) { failOn = threshold }(failOn)
+
+	for _, threshold := range failOnValues {
+		t.Run(threshold, func(t *testing.T) {
+			failOn = threshold
+			var out bytes.Buffer
+			if err := printJUnit(&out, results); err != nil {
+				t.Fatal(err)
+			}
+			var report junitTestSuites
+			if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+				t.Fatalf("output is not valid xml: %s\n%s", err, out.String())
+			}
+			if len(report.Suites) != 1 || report.Suites[0].Name != "test-pipeline" {
+				t.Fatalf("wanted a testsuite for test-pipeline only but got %+v", report.Suites)
+			}
+			suite := report.Suites[0]
+			if suite.Tests != len(pipelineValidations) || len(suite.TestCases) != len(pipelineValidations) {
+				t.Errorf("got %d testcases but wanted %d", len(suite.TestCases), len(pipelineValidations))
+			}
+			failures := 0
+			for _, severity := range highest {
+				if meetsThreshold(severity, threshold) {
+					failures++
+				}
+			}
+			if suite.Failures != failures || report.Failures != failures {
+				t.Errorf("got %d failures but wanted %d", suite.Failures, failures)
+			}
+
+			for i, tc := range suite.TestCases {
+				rule := pipelineValidations[i].rule
+				text := strings.Join(lines[rule], "\n")
+				fails := meetsThreshold(highest[rule], threshold)
+				switch {
+				case tc.Name != lookupRule(pipelineValidations[i].rule).name:
+					t.Errorf("got testcase %s but wanted %s", tc.Name, lookupRule(pipelineValidations[i].rule).name)
+				case fails && tc.Failure == nil:
+					t.Errorf("wanted %s to fail but it passed", tc.Name)
+				case fails && (tc.Failure.Text != text || tc.Failure.Type != highest[rule]):
+					t.Errorf("got %s failure %q for %s but wanted %s %q", tc.Failure.Type, tc.Failure.Text, tc.Name, highest[rule], text)
+				case !fails && tc.Failure != nil:
+					t.Errorf("wanted %s to pass but it failed with %q", tc.Name, tc.Failure.Text)
+				case !fails && tc.SystemOut != text:
+					t.Errorf("got system-out %q for %s but wanted %q", tc.SystemOut, tc.Name, text)
+				}
+			}
+		})
+	}
+}
+
+// diagnostics that are found while the object is read, e.g. unreadable, must be reported as testcases of their own
+func TestPrintJUnitUnreadable(t *testing.T) {
+	o := invalidObject{kind: "Task", name: "broken", namespace: "team-a", err: errors.New("converting Task broken")}
+	var out bytes.Buffer
+	if err := printJUnit(&out, []pipelineResult{o.result()}); err != nil {
+		t.Fatal(err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid xml: %s\n%s", err, out.String())
+	}
+	if len(report.Suites) != 1 || report.Suites[0].Name != "team-a/broken" {
+		t.Fatalf("wanted a testsuite for team-a/broken only but got %+v", report.Suites)
+	}
+	suite := report.Suites[0]
+	tc := suite.TestCases[len(suite.TestCases)-1]
+	if tc.Name != "readability validation" || tc.Failure == nil || tc.Failure.Text != "team-a/broken: error: converting Task broken" {
+		t.Errorf("wanted the readability validation to fail with the unreadable diagnostic but got %+v", tc)
+	}
+	if suite.Failures != 1 {
+		t.Errorf("got %d failures but wanted 1", suite.Failures)
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Flags that override the kubeconfig, the same way as kubectl does
+var (
+	kubeContext string
+	kubeCluster string
+	kubeUser    string
+)
+
+// Returns the client config that is loaded with the standard client-go rules. The --kubeconfig file is used if it
+// is provided, otherwise the files in $KUBECONFIG are merged, falling back to $HOME/.kube/config. When none of them
+// exist, e.g. in a pod, the in-cluster service account config is used. --context, --cluster and --user override the
+// current context of the kubeconfig.
+func clientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: kubeContext,
+		Context:        clientcmdapi.Context{Cluster: kubeCluster, AuthInfo: kubeUser},
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
+
+// Returns the namespace that mario works in when --namespace is not provided, which is the namespace
+// of the current context, the namespace of the service account in a pod, or default.
+func contextNamespace(config clientcmd.ClientConfig) (string, error) {
+	ns, _, err := config.Namespace()
+	if err != nil {
+		return "", fmt.Errorf("loading the namespace from kubeconfig: %w", err)
+	}
+	return ns, nil
+}
+
+// Given the client config, it returns a dynamic client
+func GetDynamicClient(config clientcmd.ClientConfig) (dynamic.Interface, error) {
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	return dynamic.NewForConfig(restConfig)
+}
+
+// Given the client config, it returns a discovery client
+func GetDiscoveryClient(config clientcmd.ClientConfig) (discovery.DiscoveryInterface, error) {
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	return discovery.NewDiscoveryClientForConfig(restConfig)
+}
+
+// Returns the namespace of --namespace, or the namespace of the current context when it is not provided. It is
+// the namespace that the objects are validated in unless --all-namespaces is provided.
+func selectedNamespace(config clientcmd.ClientConfig) (string, error) {
+	if namespace != "" {
+		return namespace, nil
+	}
+	return contextNamespace(config)
+}
+
+// Connects to the cluster of the kubeconfig and discovers the versions of tekton.dev that it serves. It returns the
+// dynamic client and the namespace that selectedNamespace returns.
+func connect() (dynamic.Interface, string, error) {
+	config := clientConfig()
+	if err := checkOverrides(config); err != nil {
+		return nil, "", err
+	}
+	ns, err := selectedNamespace(config)
+	if err != nil {
+		return nil, "", kubeconfigError(err)
+	}
+	client, err := GetDynamicClient(config)
+	if err != nil {
+		return nil, "", kubeconfigError(err)
+	}
+	discoveryClient, err := GetDiscoveryClient(config)
+	if err != nil {
+		return nil, "", kubeconfigError(err)
+	}
+	if servedVersions, err = discoverVersions(discoveryClient); err != nil {
+		return nil, "", clusterError(err)
+	}
+	return client, ns, nil
+}
+
+// Ensures that the kubeconfig has the context, cluster and user that --context, --cluster and --user name.
+// client-go reports them as plain errors, which could not be told apart from the errors of the cluster.
+func checkOverrides(config clientcmd.ClientConfig) error {
+	raw, err := config.RawConfig()
+	if err != nil {
+		return kubeconfigError(fmt.Errorf("loading kubeconfig: %w", err))
+	}
+	if _, ok := raw.Contexts[kubeContext]; kubeContext != "" && !ok {
+		return usageError(fmt.Errorf("the context %s does not exist in the kubeconfig", kubeContext))
+	}
+	if _, ok := raw.Clusters[kubeCluster]; kubeCluster != "" && !ok {
+		return usageError(fmt.Errorf("the cluster %s does not exist in the kubeconfig", kubeCluster))
+	}
+	if _, ok := raw.AuthInfos[kubeUser]; kubeUser != "" && !ok {
+		return usageError(fmt.Errorf("the user %s does not exist in the kubeconfig", kubeUser))
+	}
+	return nil
+}
+
+// Returns the error of loading the kubeconfig with the code that mario exits with. A kubeconfig which is missing or
+// invalid, e.g. it does not have the --context, --cluster or --user that is provided, is a usage error. The other
+// errors, e.g. a client that can not be built for the cluster, are cluster errors.
+func kubeconfigError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return usageError(err)
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if clientcmd.IsConfigurationInvalid(e) {
+			return usageError(err)
+		}
+	}
+	return clusterError(err)
+}
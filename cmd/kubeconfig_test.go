@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var yKubeconfigA = `apiVersion: v1
+kind: Config
+current-context: laptop
+clusters:
+- name: laptop
+  cluster:
+    server: https://laptop.example.com
+contexts:
+- name: laptop
+  context:
+    cluster: laptop
+    user: me
+    namespace: team-a
+users:
+- name: me
+  user:
+    token: laptop-token
+`
+
+var yKubeconfigB = `apiVersion: v1
+kind: Config
+clusters:
+- name: staging
+  cluster:
+    server: https://staging.example.com
+contexts:
+- name: staging
+  context:
+    cluster: staging
+    user: ci
+    namespace: team-b
+users:
+- name: ci
+  user:
+    token: ci-token
+`
+
+func TestClientConfig(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	writeFile(t, a, yKubeconfigA)
+	writeFile(t, b, yKubeconfigB)
+	t.Setenv("KUBECONFIG", a+string(filepath.ListSeparator)+b)
+
+	defer func(path, context, cluster, user string) {
+		kubeconfig, kubeContext, kubeCluster, kubeUser = path, context, cluster, user
+	}(kubeconfig, kubeContext, kubeCluster, kubeUser)
+
+	testCases := []struct {
+		name       string
+		kubeconfig string
+		context    string
+		cluster    string
+		user       string
+		host       string
+		token      string
+		namespace  string
+	}{
+		{name: "current context of the merged KUBECONFIG", host: "https://laptop.example.com", token: "laptop-token", namespace: "team-a"},
+		{name: "context from the second file", context: "staging", host: "https://staging.example.com", token: "ci-token", namespace: "team-b"},
+		{name: "cluster and user overrides", cluster: "staging", user: "ci", host: "https://staging.example.com", token: "ci-token", namespace: "team-a"},
+		{name: "explicit kubeconfig", kubeconfig: b, context: "staging", host: "https://staging.example.com", token: "ci-token", namespace: "team-b"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeconfig, kubeContext, kubeCluster, kubeUser = tc.kubeconfig, tc.context, tc.cluster, tc.user
+			config := clientConfig()
+			restConfig, err := config.ClientConfig()
+			if err != nil {
+				t.Fatalf("did not expect error but got: %s", err)
+			}
+			if restConfig.Host != tc.host || restConfig.BearerToken != tc.token {
+				t.Errorf("got %s with token %s but wanted %s with token %s", restConfig.Host, restConfig.BearerToken, tc.host, tc.token)
+			}
+			ns, err := contextNamespace(config)
+			if err != nil {
+				t.Fatalf("did not expect error but got: %s", err)
+			}
+			if ns != tc.namespace {
+				t.Errorf("got namespace %s but wanted %s", ns, tc.namespace)
+			}
+		})
+	}
+}
+
+// a context that is not in the kubeconfig must be reported instead of silently using another one
+func TestClientConfigMissingContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	writeFile(t, path, yKubeconfigA)
+
+	defer func(path, context string) { kubeconfig, kubeContext = path, context }(kubeconfig, kubeContext)
+	kubeconfig, kubeContext = path, "production"
+	if _, err := GetDynamicClient(clientConfig()); err == nil {
+		t.Error("expected an error for the missing context")
+	}
+}
+
+// without --namespace the objects of the namespace of the current context must be validated, not of all the namespaces
+func TestSelectedNamespace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	writeFile(t, path, yKubeconfigA)
+
+	defer func(path, ns string) { kubeconfig, namespace = path, ns }(kubeconfig, namespace)
+	kubeconfig = path
+	for flag, want := range map[string]string{"": "team-a", "team-b": "team-b"} {
+		namespace = flag
+		ns, err := selectedNamespace(clientConfig())
+		if err != nil {
+			t.Fatalf("did not expect error but got: %s", err)
+		}
+		if ns != want {
+			t.Errorf("got namespace %q with --namespace=%q but wanted %q", ns, flag, want)
+		}
+	}
+}
+
+// a kubeconfig which is missing or does not have the context, cluster or user of the flags is a usage error,
+// only the errors of talking to the api server are cluster errors
+func TestConnectExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	path, unreachable := filepath.Join(dir, "config"), filepath.Join(dir, "unreachable")
+	writeFile(t, path, yKubeconfigA)
+	writeFile(t, unreachable, strings.Replace(yKubeconfigA, "https://laptop.example.com", "https://127.0.0.1:1", 1))
+
+	defer func(path, context, cluster, user, ns string) {
+		kubeconfig, kubeContext, kubeCluster, kubeUser, namespace = path, context, cluster, user, ns
+	}(kubeconfig, kubeContext, kubeCluster, kubeUser, namespace)
+
+	testCases := []struct {
+		name       string
+		kubeconfig string
+		context    string
+		cluster    string
+		user       string
+		namespace  string
+		want       int
+	}{
+		{name: "missing kubeconfig file", kubeconfig: filepath.Join(dir, "missing"), want: exitUsage},
+		{name: "missing context", kubeconfig: path, context: "production", want: exitUsage},
+		{name: "missing context with a namespace", kubeconfig: path, context: "production", namespace: "team-a", want: exitUsage},
+		{name: "missing cluster", kubeconfig: path, cluster: "production", namespace: "team-a", want: exitUsage},
+		{name: "missing user", kubeconfig: path, user: "admin", namespace: "team-a", want: exitUsage},
+		{name: "unreachable api server", kubeconfig: unreachable, want: exitCluster},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeconfig, kubeContext, kubeCluster, kubeUser, namespace = tc.kubeconfig, tc.context, tc.cluster, tc.user, tc.namespace
+			_, _, err := connect()
+			if got := exitCode(err); got != tc.want {
+				t.Errorf("got exit code %d for %v but wanted %d", got, err, tc.want)
+			}
+		})
+	}
+}
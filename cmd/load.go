@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// A single yaml document and the source it was read from. err is set when the document can not be parsed,
+// in which case line is where the parser gave up.
+type document struct {
+	content []byte
+	source  source
+	err     error
+	line    int
+}
+
+// Reads all the yaml documents from the given files and directories. Directories are walked
+// recursively and only the files with .yaml, .yml or .json extension are read. A file which is given
+// more than once, e.g. by itself and through its directory, is only read once.
+func readDocuments(paths []string) (docs []document, err error) {
+	read := make(map[string]bool)
+	for _, path := range paths {
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || (p != path && !isManifestFile(p)) {
+				return nil
+			}
+			abs, err := filepath.Abs(p)
+			if err != nil {
+				return err
+			}
+			if read[abs] {
+				return nil
+			}
+			read[abs] = true
+			fileDocs, err := readFileDocuments(p)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, fileDocs...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// Splits a (possibly multi-document) yaml file into its documents. Empty documents are dropped.
+// The yaml nodes of the documents are kept so that the positions of the fields are known.
+func readFileDocuments(path string) (docs []document, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	decoder := yamlv3.NewDecoder(f)
+	for {
+		node := &yamlv3.Node{}
+		err := decoder.Decode(node)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// the decoder can not recover from a syntax error, so the rest of the file is skipped
+			docs = append(docs, document{source: source{file: path}, err: err, line: yamlErrorLine(err)})
+			break
+		}
+		if len(node.Content) == 0 || node.Content[0].Tag == "!!null" {
+			continue
+		}
+		content, err := yamlv3.Marshal(node)
+		if err != nil {
+			docs = append(docs, document{source: source{file: path, node: node}, err: err})
+			continue
+		}
+		docs = append(docs, document{content: content, source: source{file: path, node: node}})
+	}
+	return docs, nil
+}
+
+var yamlErrorLineRegex = regexp.MustCompile(`^yaml: line (\d+):`)
+
+// Returns the line that a yaml syntax error points to, or 0 if the error does not have a line
+func yamlErrorLine(err error) int {
+	m := yamlErrorLineRegex.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
+
+// Returns true if the file extension is one of the manifest extensions
+func isManifestFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// Converts a yaml or json document into an unstructured object. It returns nil if the document
+// does not contain any object, e.g. it only has comments.
+func decodeDocument(b []byte) (*unstructured.Unstructured, error) {
+	jObject, err := yaml.ToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	if string(jObject) == "null" {
+		return nil, nil
+	}
+	object, err := runtime.Decode(unstructured.UnstructuredJSONScheme, jObject)
+	if err != nil {
+		return nil, err
+	}
+	uObject, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return nil, errors.New("unstructured.Unstructured expected")
+	}
+	return uObject, nil
+}
+
+// Tekton objects that are read from manifests, sorted by their kind. The documents that can not
+// be parsed or converted are kept as invalid objects so that they are reported along the results.
+type manifestSet struct {
+	pipelines    []extendedPipeline
+	sources      []source // sources of the pipelines, in the same order
+	tasks        []extendedTask
+	taskSources  []source // sources of the tasks, in the same order
+	clusterTasks []extendedClusterTask
+	ctSources    []source // sources of the clusterTasks, in the same order
+	pipelineRuns []extendedPipelineRun
+	runSources   []source // sources of the pipelineRuns, in the same order
+	// tekton triggers objects, with the sources of the ones that are validated
+	triggerTemplates []triggerTemplate
+	templateSources  []source
+	triggerBindings  []triggerBinding // TriggerBindings and ClusterTriggerBindings
+	eventListeners   []eventListener
+	listenerSources  []source
+	triggers         []trigger
+	triggerSources   []source
+	invalid          []invalidObject
+
+	// true when the ClusterTriggerBindings can not be listed, so that the refs to them are not reported
+	unknownClusterBindings bool
+}
+
+// Converts the given documents into typed objects and sorts them by kind. Documents of any other kind are ignored.
+func setupManifests(docs []document) (set manifestSet) {
+	for _, doc := range docs {
+		if doc.err != nil {
+			set.invalid = append(set.invalid, invalidDocument(doc, fmt.Errorf("parsing %s: %w", doc.source.file, doc.err)))
+			continue
+		}
+		uObject, err := decodeDocument(doc.content)
+		if err != nil {
+			set.invalid = append(set.invalid, invalidDocument(doc, fmt.Errorf("decoding %s: %w", doc.source.file, err)))
+			continue
+		}
+		if uObject == nil {
+			continue
+		}
+		switch uObject.GetKind() {
+		case "Pipeline":
+			eP, err := toPipeline(uObject)
+			if err != nil {
+				set.invalid = append(set.invalid, invalidManifest(uObject, doc, err))
+				continue
+			}
+			set.pipelines = append(set.pipelines, eP)
+			set.sources = append(set.sources, doc.source)
+		case "Task":
+			eT, err := toTask(uObject)
+			if err != nil {
+				set.invalid = append(set.invalid, invalidManifest(uObject, doc, err))
+				continue
+			}
+			set.tasks = append(set.tasks, eT)
+			set.taskSources = append(set.taskSources, doc.source)
+		case "ClusterTask":
+			var eCT extendedClusterTask
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(uObject.Object, &eCT); err != nil {
+				set.invalid = append(set.invalid, invalidManifest(uObject, doc, err))
+				continue
+			}
+			set.clusterTasks = append(set.clusterTasks, eCT)
+			set.ctSources = append(set.ctSources, doc.source)
+		case "PipelineRun":
+			ePR, err := toPipelineRun(uObject)
+			if err != nil {
+				set.invalid = append(set.invalid, invalidManifest(uObject, doc, err))
+				continue
+			}
+			set.pipelineRuns = append(set.pipelineRuns, ePR)
+			set.runSources = append(set.runSources, doc.source)
+		default:
+			if err := set.addTriggerObject(uObject, doc.source); err != nil {
+				set.invalid = append(set.invalid, invalidManifest(uObject, doc, err))
+			}
+		}
+	}
+	return set
+}
+
+// Reads and sorts the objects from the given files, directories and glob patterns
+func loadManifests(paths []string) (manifestSet, error) {
+	expanded, err := expandPaths(paths)
+	if err != nil {
+		return manifestSet{}, err
+	}
+	docs, err := readDocuments(expanded)
+	if err != nil {
+		return manifestSet{}, err
+	}
+	return setupManifests(docs), nil
+}
+
+// Reads the tasks and clusterTasks from the given files, directories and glob patterns. The objects
+// that can not be read are returned as invalid objects.
+func loadTasks(paths []string) ([]extendedTask, []extendedClusterTask, []invalidObject, error) {
+	set, err := loadManifests(paths)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return set.tasks, set.clusterTasks, set.invalid, nil
+}
+
+// Expands the glob patterns in the given paths. Paths without any glob meta character are kept as they are.
+func expandPaths(paths []string) (expanded []string, err error) {
+	for _, path := range paths {
+		if !strings.ContainsAny(path, "*?[") {
+			expanded = append(expanded, path)
+			continue
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", path)
+		}
+		expanded = append(expanded, matches...)
+	}
+	return expanded, nil
+}
+
+// Returns the local tasks followed by the tasks of the cluster which are not shadowed by a local task with the same name
+func mergeTasks(local, cluster []extendedTask) []extendedTask {
+	merged := append([]extendedTask{}, local...)
+	for _, ct := range cluster {
+		if findTask(local, ct.getName()) == nil {
+			merged = append(merged, ct)
+		}
+	}
+	return merged
+}
+
+// Returns the local clusterTasks followed by the clusterTasks of the cluster which are not shadowed by a local clusterTask with the same name
+func mergeClusterTasks(local, cluster []extendedClusterTask) []extendedClusterTask {
+	merged := append([]extendedClusterTask{}, local...)
+	for _, cct := range cluster {
+		if findClusterTask(local, cct.getName()) == nil {
+			merged = append(merged, cct)
+		}
+	}
+	return merged
+}
+
+// Returns the task with the given name or nil if there is no such task
+func findTask(tasks []extendedTask, name string) *extendedTask {
+	for i := range tasks {
+		if tasks[i].getName() == name {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+// Returns the clusterTask with the given name or nil if there is no such clusterTask
+func findClusterTask(clusterTasks []extendedClusterTask, name string) *extendedClusterTask {
+	for i := range clusterTasks {
+		if clusterTasks[i].getName() == name {
+			return &clusterTasks[i]
+		}
+	}
+	return nil
+}
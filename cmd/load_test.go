@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var yTasks = `---
+apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: task-a
+spec:
+  params:
+    - name: param1
+---
+# only a comment
+---
+apiVersion: tekton.dev/v1beta1
+kind: ClusterTask
+metadata:
+  name: task-b
+spec:
+  params:
+    - name: param3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-task
+`
+
+var yTaskFinally = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: task-finally
+`
+
+// tasks and clusterTasks must be read from multi-document files and
+// directories must be walked recursively
+func TestLoadTasks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "tasks.yaml"), yTasks)
+	writeFile(t, filepath.Join(dir, "nested", "deeper", "finally.yml"), yTaskFinally)
+	writeFile(t, filepath.Join(dir, "nested", "README.md"), "# not a manifest")
+
+	eTasks, eClusterTasks, invalid, err := loadTasks([]string{dir})
+	if err != nil {
+		t.Fatalf("did not expect error but got: %s", err)
+	}
+	if len(invalid) != 0 {
+		t.Errorf("did not expect invalid objects but got %v", invalid)
+	}
+	var taskNames, clusterTaskNames []string
+	for _, et := range eTasks {
+		taskNames = append(taskNames, et.getName())
+	}
+	for _, ect := range eClusterTasks {
+		clusterTaskNames = append(clusterTaskNames, ect.getName())
+	}
+	if len(taskNames) != 2 || taskNames[0] != "task-finally" || taskNames[1] != "task-a" {
+		t.Errorf("got tasks %v but wanted [task-finally task-a]", taskNames)
+	}
+	if len(clusterTaskNames) != 1 || clusterTaskNames[0] != "task-b" {
+		t.Errorf("got clusterTasks %v but wanted [task-b]", clusterTaskNames)
+	}
+
+	// the tasks that are loaded from disk must be usable by the validations
+	tPipeline := testPipeline(t, yPipeline)
+	got := tPipeline.ValidateTaskRefs(eTasks, eClusterTasks)
+	assertion(t, got, nil)
+}
+
+// documents must be sorted by kind and glob patterns must be expanded
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pipeline.yaml"), yPipeline+"---\n"+yTasks)
+	writeFile(t, filepath.Join(dir, "run.yaml"), `apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  name: test-pipeline-run
+spec:
+  pipelineRef:
+    name: test-pipeline
+`)
+	writeFile(t, filepath.Join(dir, "finally.yml"), yTaskFinally)
+
+	set, err := loadManifests([]string{filepath.Join(dir, "*.yaml")})
+	if err != nil {
+		t.Fatalf("did not expect error but got: %s", err)
+	}
+	if len(set.pipelines) != 1 || set.pipelines[0].GetName() != "test-pipeline" {
+		t.Errorf("got %d pipelines but wanted test-pipeline only", len(set.pipelines))
+	}
+	if len(set.tasks) != 1 || len(set.clusterTasks) != 1 || len(set.pipelineRuns) != 1 {
+		t.Errorf("got %d tasks, %d clusterTasks and %d pipelineRuns but wanted one of each", len(set.tasks), len(set.clusterTasks), len(set.pipelineRuns))
+	}
+
+	_, err = loadManifests([]string{filepath.Join(dir, "*.json")})
+	if err == nil {
+		t.Errorf("wanted an error for a glob without any match but did not get any")
+	}
+}
+
+// a file which is given more than once, e.g. through -f and --tasks-file, must only be read and reported once
+func TestValidateOfflineDuplicatePaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "all.yaml")
+	writeFile(t, file, yPipeline+"---\n"+yTasks+"---\n"+yUnconvertiblePipeline)
+
+	set, err := loadManifests([]string{file, dir + "/./all.yaml", dir, filepath.Join(dir, "*.yaml")})
+	if err != nil {
+		t.Fatalf("did not expect error but got: %s", err)
+	}
+	if len(set.pipelines) != 1 || len(set.tasks) != 1 || len(set.invalid) != 1 {
+		t.Fatalf("got %d pipelines, %d tasks and %d invalid objects but wanted one of each", len(set.pipelines), len(set.tasks), len(set.invalid))
+	}
+
+	defer func(files []string, format string) {
+		tasksFiles, outputFormat = files, format
+	}(tasksFiles, outputFormat)
+	tasksFiles, outputFormat = []string{file}, "json"
+	var out bytes.Buffer
+	validateOffline(&out, set)
+	var report jsonReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid json: %s\n%s", err, out.String())
+	}
+	var names []string
+	for _, p := range report.Pipelines {
+		names = append(names, p.Name)
+	}
+	if len(names) != 2 || names[0] != "test-pipeline" || names[1] != "unconvertible-pipeline" {
+		t.Errorf("got results for %v but wanted [test-pipeline unconvertible-pipeline]", names)
+	}
+}
+
+// tasks that are provided locally shadow the ones with the same name in the cluster
+func TestMergeTasks(t *testing.T) {
+	local := []extendedTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-a", Namespace: "local"}},
+	}
+	cluster := []extendedTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-a", Namespace: "cluster"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-b", Namespace: "cluster"}},
+	}
+	merged := mergeTasks(local, cluster)
+	if len(merged) != 2 || merged[0].GetNamespace() != "local" || merged[1].getName() != "task-b" {
+		t.Errorf("got %v but wanted the local task-a and the cluster task-b", merged)
+	}
+}
+
+func TestLoadTasksMissingFile(t *testing.T) {
+	_, _, _, err := loadTasks([]string{filepath.Join(t.TempDir(), "missing.yaml")})
+	if err == nil {
+		t.Errorf("wanted an error for a missing file but did not get any")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
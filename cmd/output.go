@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The supported values of --output
+var outputFormats = []string{"text", "json", "sarif", "junit"}
+
+// The object that a result reports on, e.g. a pipeline, a pipelineRun or a task, and the source that it was read
+// from. kind is empty for the objects that are read without their TypeMeta.
+type resultSubject struct {
+	kind      string
+	name      string
+	namespace string
+	source    source
+}
+
+// Returns the subject of an object with the given kind and metadata
+func newSubject(kind string, meta metav1.ObjectMeta, src source) resultSubject {
+	return resultSubject{kind: kind, name: meta.GetName(), namespace: meta.GetNamespace(), source: src}
+}
+
+// Returns namespace/name of the subject, or its name if it does not have a namespace
+func (s resultSubject) id() string {
+	if s.namespace == "" {
+		return s.name
+	}
+	return s.namespace + "/" + s.name
+}
+
+// The outcome of validating a single object. rules lists the rules that the object was checked against, which
+// defaults to the rules of pipelineValidations.
+type pipelineResult struct {
+	subject     resultSubject
+	diagnostics []diagnostic
+	rules       []string
+}
+
+// Returns the rules that the subject of the result was checked against
+func (r pipelineResult) checks() []string {
+	if r.rules != nil {
+		return r.rules
+	}
+	var rules []string
+	for _, v := range pipelineValidations {
+		rules = append(rules, v.rule)
+	}
+	return rules
+}
+
+// The schema of --output json. It is meant to be stable, new fields may be added but the existing ones do not change.
+type (
+	jsonReport struct {
+		Pipelines []jsonPipeline `json:"pipelines"`
+	}
+	jsonPipeline struct {
+		Kind        string           `json:"kind,omitempty"`
+		Name        string           `json:"name"`
+		Namespace   string           `json:"namespace,omitempty"`
+		Diagnostics []jsonDiagnostic `json:"diagnostics"`
+	}
+	jsonDiagnostic struct {
+		Rule         string `json:"rule"`
+		Severity     string `json:"severity"`
+		PipelineTask string `json:"pipelineTask,omitempty"`
+		Field        string `json:"field,omitempty"`
+		Subject      string `json:"subject,omitempty"`
+		Message      string `json:"message"`
+		Suggestion   string `json:"suggestion,omitempty"`
+		File         string `json:"file,omitempty"`
+		Line         int    `json:"line,omitempty"`
+		Column       int    `json:"column,omitempty"`
+	}
+)
+
+// Prints the results in the format that is chosen by --output
+func printResults(w io.Writer, results []pipelineResult) error {
+	switch outputFormat {
+	case "json":
+		return printJSON(w, results)
+	case "sarif":
+		return printSARIF(w, results)
+	case "junit":
+		return printJUnit(w, results)
+	}
+	for _, r := range results {
+		printErrors(w, r)
+	}
+	return nil
+}
+
+// Prints the results as a single json document
+func printJSON(w io.Writer, results []pipelineResult) error {
+	report := jsonReport{Pipelines: []jsonPipeline{}}
+	for _, r := range results {
+		jp := jsonPipeline{Kind: r.subject.kind, Name: r.subject.name, Namespace: r.subject.namespace, Diagnostics: []jsonDiagnostic{}}
+		for _, d := range r.diagnostics {
+			jp.Diagnostics = append(jp.Diagnostics, jsonDiagnostic{
+				Rule:         d.rule,
+				Severity:     d.severity,
+				PipelineTask: d.location.pipelineTask,
+				Field:        d.location.field,
+				Subject:      d.subject,
+				Message:      d.message,
+				Suggestion:   d.suggestion,
+				File:         d.location.file,
+				Line:         d.location.line,
+				Column:       d.location.column,
+			})
+		}
+		report.Pipelines = append(report.Pipelines, jp)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// json output must have one entry per pipeline with an entry per diagnostic
+func TestPrintJSON(t *testing.T) {
+	tPipeline := testPipeline(t, yPipeline)
+	cTasks := []extendedTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
+	}
+	cClusterTasks := []extendedClusterTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+	}
+	results := []pipelineResult{newPipelineResult(&tPipeline, source{}, cTasks, cClusterTasks, nil)}
+
+	var out bytes.Buffer
+	if err := printJSON(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid json: %s\n%s", err, out.String())
+	}
+	if len(report.Pipelines) != 1 || report.Pipelines[0].Name != "test-pipeline" {
+		t.Fatalf("wanted test-pipeline only but got %+v", report.Pipelines)
+	}
+
+	want := []jsonDiagnostic{
+		{Rule: "task-ref", Severity: "error", PipelineTask: "task-a", Subject: "task-a", Message: "task-a refers to the task task-a which is not found"},
+		{Rule: "unused-params", Severity: "warning", Field: "spec.params", Subject: "param-not-needed", Message: "the param param-not-needed is not used by any pipelineTask"},
+		{Rule: "unused-workspaces", Severity: "warning", Field: "spec.workspaces", Subject: "ws-no-needed", Message: "the workspace ws-no-needed is not used by any pipelineTask"},
+	}
+	for _, w := range want {
+		found := false
+		for _, d := range report.Pipelines[0].Diagnostics {
+			if d == w {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("wanted diagnostic %+v but got %+v", w, report.Pipelines[0].Diagnostics)
+		}
+	}
+}
+
+func TestPrintJSONWithoutFindings(t *testing.T) {
+	var out bytes.Buffer
+	if err := printJSON(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "{\n  \"pipelines\": []\n}\n" {
+		t.Errorf("got %q but wanted an empty list of pipelines", got)
+	}
+}
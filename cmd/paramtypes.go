@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tknv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// Ensures that the types of the params line up. It checks that:
+//   - the value that a pipelineTask supplies has the type that the task declares for the param
+//   - object values supply the keys that are declared in the properties of the param
+//   - $(params.x[*]), $(params.x[i]) and $(params.x.key) are only used on array and object params
+//   - the defaults in spec.params of the pipeline match their declared types
+func (p *extendedPipeline) ValidateParamTypes(cTasks []extendedTask, cClusterTasks []extendedClusterTask, rTasks resolvedTasks) []diagnostic {
+	pParams := make(map[string]tknv1beta1.ParamSpec)
+	var diags []diagnostic
+
+	for _, param := range p.Spec.Params {
+		pParams[param.Name] = param
+		for _, d := range defaultTypeErrors(param) {
+			d.location = location{field: "spec.params"}
+			diags = append(diags, d)
+		}
+	}
+
+	for _, pt := range allPipelineTasks(p) {
+		var pTaskDiags []diagnostic
+		for _, v := range stringValues([]interface{}{pt.Params, pt.Matrix, pt.WhenExpressions}) {
+			pTaskDiags = append(pTaskDiags, referenceTypeErrors(v, pParams)...)
+		}
+
+		if ct := resolveTask(pt, cTasks, cClusterTasks, rTasks); ct != nil {
+			cParams := make(map[string]tknv1beta1.ParamSpec)
+			for _, cp := range ct.getParams() {
+				cParams[cp.Name] = cp
+			}
+			for _, param := range pt.Params {
+				cp, ok := cParams[param.Name]
+				if !ok {
+					continue
+				}
+				pTaskDiags = append(pTaskDiags, valueTypeErrors(param, cp, pParams)...)
+			}
+		}
+
+		for _, d := range pTaskDiags {
+			d.location = location{pipelineTask: pt.Name}
+			if !includesDiagnostic(diags, d) {
+				diags = append(diags, d)
+			}
+		}
+	}
+	return sortDiagnostics(diags)
+}
+
+// Returns a param-types diagnostic about the given param. The location is set by the caller.
+func paramTypeDiagnostic(param, message string) diagnostic {
+	return diagnostic{rule: ruleParamTypes, severity: severityError, subject: param, message: message}
+}
+
+// Returns the type of a param the same way tekton defaults it. If the type is not set, it is
+// inferred from the properties or the default value and falls back to string.
+func declaredParamType(ps tknv1beta1.ParamSpec) tknv1beta1.ParamType {
+	switch {
+	case ps.Type != "":
+		return ps.Type
+	case ps.Properties != nil:
+		return tknv1beta1.ParamTypeObject
+	case ps.Default != nil && ps.Default.Type != "":
+		return ps.Default.Type
+	}
+	return tknv1beta1.ParamTypeString
+}
+
+// Returns the type of the value that is supplied for a param. A value which is nothing but
+// $(params.x[*]) has the type of the pipeline param x.
+func suppliedParamType(value tknv1beta1.ParamValue, pParams map[string]tknv1beta1.ParamSpec) tknv1beta1.ParamType {
+	if value.Type != tknv1beta1.ParamTypeString {
+		return value.Type
+	}
+	refs := paramReferences(value.StringVal)
+	if len(refs) == 1 && refs[0].index == "[*]" && strings.TrimSpace(value.StringVal) == fmt.Sprintf("$(params.%s[*])", refs[0].name) {
+		if pp, ok := pParams[refs[0].name]; ok {
+			return declaredParamType(pp)
+		}
+	}
+	return tknv1beta1.ParamTypeString
+}
+
+// Checks the value that a pipelineTask supplies for a param against the param of the task
+func valueTypeErrors(param tknv1beta1.Param, cp tknv1beta1.ParamSpec, pParams map[string]tknv1beta1.ParamSpec) (errs []diagnostic) {
+	want := declaredParamType(cp)
+	got := suppliedParamType(param.Value, pParams)
+	if got != want {
+		return []diagnostic{paramTypeDiagnostic(param.Name, fmt.Sprintf("%s is %s %s param but %s %s value is supplied", param.Name, article(want), want, article(got), got))}
+	}
+	if want == tknv1beta1.ParamTypeObject && param.Value.Type == tknv1beta1.ParamTypeObject {
+		errs = append(errs, objectKeyErrors(param.Name, param.Value.ObjectVal, cp)...)
+	}
+	return
+}
+
+// Checks the keys of an object value against the properties of the param. Keys are only required
+// when the default of the param does not provide them.
+func objectKeyErrors(name string, value map[string]string, ps tknv1beta1.ParamSpec) (errs []diagnostic) {
+	if len(ps.Properties) == 0 {
+		return nil
+	}
+	for key := range value {
+		if _, ok := ps.Properties[key]; !ok {
+			errs = append(errs, paramTypeDiagnostic(name, fmt.Sprintf("%s has the key %s which is not declared in its properties", name, key)))
+		}
+	}
+	for key := range ps.Properties {
+		if _, ok := value[key]; ok {
+			continue
+		}
+		if ps.Default != nil {
+			if _, ok := ps.Default.ObjectVal[key]; ok {
+				continue
+			}
+		}
+		errs = append(errs, paramTypeDiagnostic(name, fmt.Sprintf("%s is missing the key %s", name, key)))
+	}
+	return
+}
+
+// Checks how the $(params.x) references in the given string use the pipeline params
+func referenceTypeErrors(s string, pParams map[string]tknv1beta1.ParamSpec) (errs []diagnostic) {
+	for _, ref := range paramReferences(s) {
+		pp, ok := pParams[ref.name]
+		if !ok {
+			continue
+		}
+		pType := declaredParamType(pp)
+		switch {
+		case ref.index == "[*]" && pType == tknv1beta1.ParamTypeString:
+			errs = append(errs, paramTypeDiagnostic(ref.name, fmt.Sprintf("$(params.%s[*]) is used but %s is a string param", ref.name, ref.name)))
+		case ref.index != "" && ref.index != "[*]" && pType != tknv1beta1.ParamTypeArray:
+			errs = append(errs, paramTypeDiagnostic(ref.name, fmt.Sprintf("$(params.%s%s) is used but %s is %s %s param", ref.name, ref.index, ref.name, article(pType), pType)))
+		case ref.key != "" && pType != tknv1beta1.ParamTypeObject:
+			errs = append(errs, paramTypeDiagnostic(ref.name, fmt.Sprintf("$(params.%s.%s) is used but %s is %s %s param", ref.name, ref.key, ref.name, article(pType), pType)))
+		case ref.key != "" && len(pp.Properties) > 0:
+			if _, ok := pp.Properties[ref.key]; !ok {
+				errs = append(errs, paramTypeDiagnostic(ref.name, fmt.Sprintf("$(params.%s.%s) is used but %s is not declared in the properties of %s", ref.name, ref.key, ref.key, ref.name)))
+			}
+		case ref.index == "" && ref.key == "" && pType == tknv1beta1.ParamTypeArray:
+			errs = append(errs, paramTypeDiagnostic(ref.name, fmt.Sprintf("%s is an array param and must be used as $(params.%s[*]) or $(params.%s[i])", ref.name, ref.name, ref.name)))
+		case ref.index == "" && ref.key == "" && pType == tknv1beta1.ParamTypeObject:
+			errs = append(errs, paramTypeDiagnostic(ref.name, fmt.Sprintf("%s is an object param and must be used as $(params.%s[*]) or $(params.%s.key)", ref.name, ref.name, ref.name)))
+		}
+	}
+	return
+}
+
+// Checks the default of a pipeline param against its declared type and properties
+func defaultTypeErrors(ps tknv1beta1.ParamSpec) (errs []diagnostic) {
+	if ps.Default == nil {
+		return nil
+	}
+	want := declaredParamType(ps)
+	if ps.Default.Type != want {
+		return []diagnostic{paramTypeDiagnostic(ps.Name, fmt.Sprintf("%s is %s %s param but its default is %s %s", ps.Name, article(want), want, article(ps.Default.Type), ps.Default.Type))}
+	}
+	if want == tknv1beta1.ParamTypeObject {
+		for _, d := range objectKeyErrors(ps.Name, ps.Default.ObjectVal, ps) {
+			d.message = "default of " + d.message
+			errs = append(errs, d)
+		}
+	}
+	return
+}
+
+// Returns the indefinite article of the param type
+func article(t tknv1beta1.ParamType) string {
+	if t == tknv1beta1.ParamTypeArray || t == tknv1beta1.ParamTypeObject {
+		return "an"
+	}
+	return "a"
+}
+
+// Returns the given strings without the duplicates, keeping the order of their first occurrence
+func uniqueStrings(slice []string) (unique []string) {
+	for _, s := range slice {
+		if !sliceIncludeString(unique, s) {
+			unique = append(unique, s)
+		}
+	}
+	return
+}
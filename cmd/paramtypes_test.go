@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"testing"
+
+	tknv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var yTypedPipeline = `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: typed-pipeline
+spec:
+  params:
+    - name: string-param
+    - name: array-param
+      type: array
+    - name: object-param
+      type: object
+      properties:
+        url: {}
+        revision: {}
+      default:
+        url: https://example.com/repo.git
+    - name: bad-default
+      type: array
+      default: "not-an-array"
+  tasks:
+    - name: task-a
+      taskRef:
+        name: task-a
+      params:
+        - name: string-arg
+          value: $(params.string-param)-$(params.array-param[0])
+        - name: array-arg
+          value: $(params.array-param[*])
+        - name: object-arg
+          value:
+            url: $(params.object-param.url)
+            branch: $(params.object-param.branch)
+        - name: array-as-string
+          value: $(params.array-param)
+        - name: string-as-array
+          value: ["$(params.string-param[*])"]
+`
+
+// the types of the supplied values, references and defaults must match the declared param types
+func TestValidateParamTypes(t *testing.T) {
+	tPipeline := testPipeline(t, yTypedPipeline)
+
+	validateParamTypesTests := []ValidateParamsTestCases{
+		{
+			name: "typed pipeline has type errors",
+			want: []string{
+				"spec.params: bad-default is an array param but its default is a string",
+				"spec.params: default of object-param is missing the key revision",
+				"task-a: array-param is an array param and must be used as $(params.array-param[*]) or $(params.array-param[i])",
+				"task-a: object-arg has the key branch which is not declared in its properties",
+				"task-a: object-arg is missing the key revision",
+				"task-a: $(params.object-param.branch) is used but branch is not declared in the properties of object-param",
+				"task-a: string-as-array is a string param but an array value is supplied",
+				"task-a: $(params.string-param[*]) is used but string-param is a string param",
+			},
+			cTasks: []extendedTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-a"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "string-arg"},
+							{Name: "array-arg", Type: tknv1beta1.ParamTypeArray},
+							{Name: "object-arg", Type: tknv1beta1.ParamTypeObject, Properties: map[string]tknv1beta1.PropertySpec{"url": {}, "revision": {}}},
+							{Name: "array-as-string"},
+							{Name: "string-as-array"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range validateParamTypesTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tPipeline.ValidateParamTypes(tc.cTasks, tc.cClusterTasks, nil)
+			assertion(t, got, tc.want)
+		})
+	}
+}
+
+func TestValidateParamTypesTestPipeline(t *testing.T) {
+	tPipeline := testPipeline(t, yPipeline)
+	cTasks := []extendedTask{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "task-a"},
+			Spec: tknv1beta1.TaskSpec{
+				Params: []tknv1beta1.ParamSpec{
+					{Name: "param1"},
+					{Name: "param2"},
+				},
+			},
+		},
+	}
+	cClusterTasks := []extendedClusterTask{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "task-b"},
+			Spec: tknv1beta1.TaskSpec{
+				Params: []tknv1beta1.ParamSpec{
+					{Name: "param3", Type: tknv1beta1.ParamTypeArray},
+				},
+			},
+		},
+	}
+	want := []string{"task-b: param3 is an array param and must be used as $(params.param3[*]) or $(params.param3[i])"}
+	assertion(t, tPipeline.ValidateParamTypes(cTasks, cClusterTasks, nil), want)
+}
@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// --offline of validate pipelinerun
+var offline bool
+
+// Checks whether the service account exists in the namespace. It returns an error which satisfies
+// apierrors.IsNotFound if it does not.
+type serviceAccountLookup func(ns, name string) error
+
+// A validation of pipelineRuns against the pipeline that they run. lookup is nil when the cluster is not available.
+type pipelineRunValidation struct {
+	rule  string
+	check func(pr *extendedPipelineRun, p *extendedPipeline, lookup serviceAccountLookup) []diagnostic
+}
+
+// The validations of the pipelineRuns, in the order that they run. The pipelineRef is checked while the pipeline
+// is looked up, before these validations run.
+var pipelineRunValidations = []pipelineRunValidation{
+	{
+		rule: ruleRunParams,
+		check: func(pr *extendedPipelineRun, p *extendedPipeline, _ serviceAccountLookup) []diagnostic {
+			return pr.ValidateRunParams(p)
+		},
+	},
+	{
+		rule: ruleRunWorkspaces,
+		check: func(pr *extendedPipelineRun, p *extendedPipeline, _ serviceAccountLookup) []diagnostic {
+			return pr.ValidateRunWorkspaces(p)
+		},
+	},
+	{
+		rule: ruleTaskRunSpecs,
+		check: func(pr *extendedPipelineRun, p *extendedPipeline, _ serviceAccountLookup) []diagnostic {
+			return pr.ValidateTaskRunSpecs(p)
+		},
+	},
+	{
+		rule: ruleServiceAccounts,
+		check: func(pr *extendedPipelineRun, _ *extendedPipeline, lookup serviceAccountLookup) []diagnostic {
+			return pr.ValidateServiceAccounts(lookup)
+		},
+	},
+}
+
+var pipelineRunCmd = &cobra.Command{
+	Use:   "pipelinerun [NAME...]",
+	Short: "Validates the pipelineRuns against their pipelines",
+	Long: `Validates the pipelineRuns that are read from --pipeline-file, or the
+	pipelineRuns with the given names in the cluster, against the pipeline that they
+	refer to through pipelineRef or embed as pipelineSpec. It ensures the following:
+	- the pipeline that pipelineRef refers to must exist
+	- params of the pipeline that don't have a default value must be supplied
+	- workspaces of the pipeline that are not optional must be bound
+	- taskRunSpecs must refer to pipelineTasks of the pipeline
+	- serviceAccountName and taskServiceAccountName must exist in the namespace
+
+	The pipelines are looked up in --pipeline-file first and then in the cluster.
+	With --offline the cluster is not used, the pipelines must be provided in
+	--pipeline-file and the service accounts are not checked.
+
+	Without names or files, all the pipelineRuns in the namespace that match
+	--selector are validated.`,
+	RunE: validateRunE(func(cmd *cobra.Command, args []string) error {
+		if offline && len(pipelineFiles) == 0 {
+			return usageError(errors.New("--pipeline-file must be provided with --offline"))
+		}
+		if len(args) > 0 && allNamespaces {
+			return usageError(errors.New("pipelineRun names can not be used with --all-namespaces"))
+		}
+		return nil
+	}, func(cmd *cobra.Command, args []string) error {
+		var set manifestSet
+		if len(pipelineFiles) > 0 {
+			var err error
+			if set, err = loadManifests(pipelineFiles); err != nil {
+				return usageError(err)
+			}
+			if set, err = selectPipelineRuns(set, args); err != nil {
+				return usageError(err)
+			}
+			if len(set.pipelineRuns) == 0 {
+				return usageError(fmt.Errorf("no pipelineRuns found in %v", pipelineFiles))
+			}
+		}
+		if offline {
+			return validatePipelineRuns(cmd.OutOrStdout(), nil, set)
+		}
+
+		client, ns, err := connect()
+		if err != nil {
+			return err
+		}
+		for i := range set.pipelineRuns {
+			if set.pipelineRuns[i].GetNamespace() == "" {
+				set.pipelineRuns[i].SetNamespace(ns)
+			}
+		}
+		if len(pipelineFiles) == 0 {
+			if allNamespaces {
+				ns = ""
+			}
+			list, err := listPipelineRuns(client, ns, args)
+			if err != nil {
+				return err
+			}
+			set.pipelineRuns, set.invalid = convertPipelineRuns(list)
+			set.runSources = make([]source, len(set.pipelineRuns))
+		}
+		return validatePipelineRuns(cmd.OutOrStdout(), client, set)
+	}),
+}
+
+func init() {
+	validateCmd.AddCommand(pipelineRunCmd)
+
+	pipelineRunCmd.Flags().BoolVar(&offline, "offline", false, "Validate the pipelineRuns against the pipelines in --pipeline-file only, without a cluster")
+}
+
+// Returns the pipelineRuns of the set, and their sources, which match --selector and have one of the given names
+func selectPipelineRuns(set manifestSet, names []string) (manifestSet, error) {
+	selector, err := labels.Parse(pipelineSelector)
+	if err != nil {
+		return set, fmt.Errorf("parsing --selector: %w", err)
+	}
+	selected := set
+	selected.pipelineRuns, selected.runSources = nil, nil
+	for i, pr := range set.pipelineRuns {
+		if (len(names) == 0 || sliceIncludeString(names, pr.GetName())) && selector.Matches(labels.Set(pr.GetLabels())) {
+			selected.pipelineRuns = append(selected.pipelineRuns, pr)
+			selected.runSources = append(selected.runSources, set.runSources[i])
+		}
+	}
+	return selected, nil
+}
+
+// Gets the pipelineRuns with the given names in the namespace, or lists the pipelineRuns in the namespace (all
+// namespaces if ns is empty) that match --selector when no name is given
+func listPipelineRuns(client dynamic.Interface, ns string, names []string) (*unstructured.UnstructuredList, error) {
+	resource := client.Resource(tektonResource("pipelineruns"))
+	if len(names) == 0 {
+		list, err := listAll(context.TODO(), resource.Namespace(ns), v1.ListOptions{LabelSelector: pipelineSelector})
+		if err != nil {
+			return nil, clusterError(fmt.Errorf("listing pipelineRuns: %w", err))
+		}
+		return list, nil
+	}
+	list := &unstructured.UnstructuredList{}
+	for _, name := range names {
+		pr, err := resource.Namespace(ns).Get(context.TODO(), name, v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, usageError(fmt.Errorf("pipelineRun %s not found in %s", name, ns))
+		}
+		if err != nil {
+			return nil, clusterError(fmt.Errorf("getting pipelineRun %s in %s: %w", name, ns, err))
+		}
+		list.Items = append(list.Items, *pr)
+	}
+	return list, nil
+}
+
+// Validates the pipelineRuns against their pipelines. The pipelines are looked up in the set and then in the
+// cluster, the client is nil when mario runs without a cluster.
+func validatePipelineRuns(w io.Writer, client dynamic.Interface, set manifestSet) error {
+	finder := pipelineFinder{local: set.pipelines, client: client, cache: make(map[string]*extendedPipeline)}
+	lookup := newServiceAccountLookup(client)
+
+	var results []pipelineResult
+	for i := range set.pipelineRuns {
+		pr := &set.pipelineRuns[i]
+		p, diags := finder.pipeline(pr)
+		results = append(results, newPipelineRunResult(pr, set.runSources[i], p, diags, lookup))
+	}
+	results = append(results, invalidResults(set.invalid)...)
+	if err := printResults(w, results); err != nil {
+		return fmt.Errorf("printing the results: %w", err)
+	}
+	return resultsError(results)
+}
+
+// Returns the lookup of the service accounts in the cluster, or nil when mario runs without a cluster
+func newServiceAccountLookup(client dynamic.Interface) serviceAccountLookup {
+	if client == nil {
+		return nil
+	}
+	return func(ns, name string) error {
+		_, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}).Namespace(ns).Get(context.TODO(), name, v1.GetOptions{})
+		return err
+	}
+}
+
+// Validates the pipelineRun against its pipeline, which is nil if it could not be found, and locates the
+// diagnostics in the source that the pipelineRun was read from
+func newPipelineRunResult(pr *extendedPipelineRun, src source, p *extendedPipeline, diags []diagnostic, lookup serviceAccountLookup) pipelineResult {
+	diags = append(diags, runPipelineRunChecks(pr, p, lookup)...)
+	src.locate(diags)
+	return pipelineResult{subject: newSubject("PipelineRun", pr.ObjectMeta, src), diagnostics: sortDiagnostics(diags), rules: pipelineRunRules()}
+}
+
+// Runs the checks of pipelineRunValidations against the pipeline, which is nil if it could not be found
+func runPipelineRunChecks(pr *extendedPipelineRun, p *extendedPipeline, lookup serviceAccountLookup) (diags []diagnostic) {
+	if p == nil {
+		return nil
+	}
+	for _, v := range pipelineRunValidations {
+		diags = append(diags, v.check(pr, p, lookup)...)
+	}
+	return
+}
+
+// Returns the rules that the pipelineRuns are checked against, starting with their pipelineRef
+func pipelineRunRules() []string {
+	rules := []string{rulePipelineRef}
+	for _, v := range pipelineRunValidations {
+		rules = append(rules, v.rule)
+	}
+	return rules
+}
+
+// Finds the pipelines that the pipelineRuns run, first in the local pipelines and then in the cluster.
+// The pipelines that are read from the cluster are cached by namespace/name.
+type pipelineFinder struct {
+	local  []extendedPipeline
+	client dynamic.Interface
+	cache  map[string]*extendedPipeline
+}
+
+// Returns the pipeline that the pipelineRun runs. It returns nil and the diagnostics that explain why when
+// the pipeline can not be found, or is resolved remotely and can not be validated.
+func (f pipelineFinder) pipeline(pr *extendedPipelineRun) (*extendedPipeline, []diagnostic) {
+	d := diagnostic{rule: rulePipelineRef, severity: severityError, location: location{field: "spec.pipelineRef"}}
+	ref := pr.Spec.PipelineRef
+	switch {
+	case pr.Spec.PipelineSpec != nil:
+		return &extendedPipeline{ObjectMeta: v1.ObjectMeta{Name: pr.GetName(), Namespace: pr.GetNamespace()}, Spec: *pr.Spec.PipelineSpec}, nil
+	case ref == nil || (ref.Name == "" && ref.Resolver == ""):
+		d.location.field = "spec"
+		d.message = fmt.Sprintf("%s has neither a pipelineRef nor a pipelineSpec", pr.GetName())
+		return nil, []diagnostic{d}
+	case ref.Resolver != "" || ref.Bundle != "":
+		d.severity, d.subject = severityWarning, ref.Name
+		d.message = fmt.Sprintf("%s is not validated because its pipeline is resolved remotely", pr.GetName())
+		return nil, []diagnostic{d}
+	}
+
+	d.subject = ref.Name
+	for i := range f.local {
+		if p := &f.local[i]; p.GetName() == ref.Name && (p.GetNamespace() == "" || p.GetNamespace() == pr.GetNamespace()) {
+			return p, nil
+		}
+	}
+	if f.client == nil {
+		d.message = fmt.Sprintf("%s refers to the pipeline %s which is not in the files", pr.GetName(), ref.Name)
+		return nil, []diagnostic{d}
+	}
+	key := pr.GetNamespace() + "/" + ref.Name
+	if p, ok := f.cache[key]; ok {
+		return p, nil
+	}
+	u, err := f.client.Resource(tektonResource("pipelines")).Namespace(pr.GetNamespace()).Get(context.TODO(), ref.Name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		d.message = fmt.Sprintf("%s refers to the pipeline %s which does not exist in %s", pr.GetName(), ref.Name, pr.GetNamespace())
+		return nil, []diagnostic{d}
+	}
+	if err != nil {
+		d.rule = ruleUnreadable
+		d.message = fmt.Sprintf("%s is not validated: getting pipeline %s: %s", pr.GetName(), ref.Name, err)
+		return nil, []diagnostic{d}
+	}
+	p, err := toPipeline(u)
+	if err != nil {
+		d.rule = ruleUnreadable
+		d.message = fmt.Sprintf("%s is not validated: converting pipeline %s: %s", pr.GetName(), ref.Name, err)
+		return nil, []diagnostic{d}
+	}
+	f.cache[key] = &p
+	return &p, nil
+}
+
+// Ensures that the pipelineRun supplies all the params of the pipeline which do not have a default value.
+// Params that the pipeline does not declare are reported as warnings.
+func (pr *extendedPipelineRun) ValidateRunParams(p *extendedPipeline) []diagnostic {
+	var supplied, declared []string
+	var diags []diagnostic
+	for _, param := range pr.Spec.Params {
+		supplied = append(supplied, param.Name)
+	}
+	for _, param := range p.Spec.Params {
+		declared = append(declared, param.Name)
+		if param.Default == nil && !sliceIncludeString(supplied, param.Name) {
+			diags = append(diags, diagnostic{
+				rule:     ruleRunParams,
+				severity: severityError,
+				location: location{field: "spec.params"},
+				subject:  param.Name,
+				message:  fmt.Sprintf("%s does not supply the param %s which %s requires", pr.GetName(), param.Name, p.GetName()),
+			})
+		}
+	}
+	for _, name := range supplied {
+		if sliceIncludeString(declared, name) {
+			continue
+		}
+		d := diagnostic{
+			rule:     ruleRunParams,
+			severity: severityWarning,
+			location: location{field: "spec.params"},
+			subject:  name,
+			message:  fmt.Sprintf("%s supplies the param %s which is not declared by %s", pr.GetName(), name, p.GetName()),
+		}
+		if suggestion := closestString(name, declared); suggestion != "" {
+			d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+		}
+		diags = append(diags, d)
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that the pipelineRun binds all the workspaces of the pipeline which are not optional.
+// Bindings of workspaces that the pipeline does not declare are reported as warnings.
+func (pr *extendedPipelineRun) ValidateRunWorkspaces(p *extendedPipeline) []diagnostic {
+	var bound, declared []string
+	var diags []diagnostic
+	for _, ws := range pr.Spec.Workspaces {
+		bound = append(bound, ws.Name)
+	}
+	for _, ws := range p.Spec.Workspaces {
+		declared = append(declared, ws.Name)
+		if !ws.Optional && !sliceIncludeString(bound, ws.Name) {
+			diags = append(diags, diagnostic{
+				rule:     ruleRunWorkspaces,
+				severity: severityError,
+				location: location{field: "spec.workspaces"},
+				subject:  ws.Name,
+				message:  fmt.Sprintf("%s does not bind the workspace %s which %s requires", pr.GetName(), ws.Name, p.GetName()),
+			})
+		}
+	}
+	for _, name := range bound {
+		if sliceIncludeString(declared, name) {
+			continue
+		}
+		d := diagnostic{
+			rule:     ruleRunWorkspaces,
+			severity: severityWarning,
+			location: location{field: "spec.workspaces"},
+			subject:  name,
+			message:  fmt.Sprintf("%s binds the workspace %s which is not declared by %s", pr.GetName(), name, p.GetName()),
+		}
+		if suggestion := closestString(name, declared); suggestion != "" {
+			d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+		}
+		diags = append(diags, d)
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that every taskRunSpec refers to a pipelineTask of the pipeline, in spec.tasks or spec.finally
+func (pr *extendedPipelineRun) ValidateTaskRunSpecs(p *extendedPipeline) []diagnostic {
+	var pTaskNames []string
+	var diags []diagnostic
+	for _, pt := range allPipelineTasks(p) {
+		pTaskNames = append(pTaskNames, pt.Name)
+	}
+	for _, spec := range pr.Spec.TaskRunSpecs {
+		if sliceIncludeString(pTaskNames, spec.PipelineTaskName) {
+			continue
+		}
+		d := diagnostic{
+			rule:     ruleTaskRunSpecs,
+			severity: severityError,
+			location: location{field: "spec.taskRunSpecs"},
+			subject:  spec.PipelineTaskName,
+			message:  fmt.Sprintf("taskRunSpecs refers to the pipelineTask %s which does not exist in %s", spec.PipelineTaskName, p.GetName()),
+		}
+		if suggestion := closestString(spec.PipelineTaskName, pTaskNames); suggestion != "" {
+			d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+		}
+		diags = append(diags, d)
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that spec.serviceAccountName and the taskServiceAccountName of the taskRunSpecs exist in the namespace
+// of the pipelineRun. It does nothing without a lookup, i.e. when mario runs without a cluster.
+func (pr *extendedPipelineRun) ValidateServiceAccounts(lookup serviceAccountLookup) []diagnostic {
+	if lookup == nil {
+		return nil
+	}
+	accounts := map[string]string{}
+	if pr.Spec.ServiceAccountName != "" {
+		accounts[pr.Spec.ServiceAccountName] = "spec.serviceAccountName"
+	}
+	for _, spec := range pr.Spec.TaskRunSpecs {
+		if _, ok := accounts[spec.TaskServiceAccountName]; !ok && spec.TaskServiceAccountName != "" {
+			accounts[spec.TaskServiceAccountName] = "spec.taskRunSpecs"
+		}
+	}
+
+	var diags []diagnostic
+	for name, field := range accounts {
+		// the pipelineRuns of TriggerTemplates may pick the service account through a param
+		if strings.Contains(name, "$(") {
+			continue
+		}
+		err := lookup(pr.GetNamespace(), name)
+		if err == nil {
+			continue
+		}
+		d := diagnostic{rule: ruleServiceAccounts, severity: severityError, location: location{field: field}, subject: name}
+		if apierrors.IsNotFound(err) {
+			d.message = fmt.Sprintf("%s uses the service account %s which does not exist in %s", pr.GetName(), name, pr.GetNamespace())
+		} else {
+			d.severity = severityWarning
+			d.message = fmt.Sprintf("the service account %s can not be checked: %s", name, strings.TrimSpace(err.Error()))
+		}
+		diags = append(diags, d)
+	}
+	return sortDiagnostics(diags)
+}
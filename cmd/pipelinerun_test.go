@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var yPipelineRun = `apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  name: test-run
+spec:
+  pipelineRef:
+    name: test-pipeline
+  serviceAccountName: builder
+  params:
+    - name: param1
+      value: value1
+    - name: param5
+      value: value5
+  workspaces:
+    - name: ws1
+      emptyDir: {}
+    - name: ws-extra
+      emptyDir: {}
+  taskRunSpecs:
+    - pipelineTaskName: task-a
+      taskServiceAccountName: deployer
+    - pipelineTaskName: task-d
+`
+
+var yInlinePipelineRun = `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: inline-run
+spec:
+  pipelineSpec:
+    params:
+      - name: message
+    workspaces:
+      - name: source
+    tasks:
+      - name: echo
+        taskSpec:
+          steps:
+            - image: ubuntu
+              script: echo $(params.message)
+  taskRunTemplate:
+    serviceAccountName: builder
+`
+
+// Returns the typed pipelineRun of the yaml
+func testPipelineRun(t *testing.T, y string) extendedPipelineRun {
+	t.Helper()
+	ePR, err := toPipelineRun(testObject(t, y, "team-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ePR
+}
+
+func TestValidatePipelineRun(t *testing.T) {
+	eP := testPipeline(t, yPipeline)
+	ePR := testPipelineRun(t, yPipelineRun)
+
+	assertion(t, ePR.ValidateRunParams(&eP), []string{
+		"spec.params: test-run does not supply the param param-finally which test-pipeline requires",
+		"spec.params: test-run does not supply the param param-not-needed which test-pipeline requires",
+		"spec.params: test-run does not supply the param param4 which test-pipeline requires",
+		"spec.params: test-run supplies the param param5 which is not declared by test-pipeline, did you mean param4?",
+	})
+	assertion(t, ePR.ValidateRunWorkspaces(&eP), []string{
+		"spec.workspaces: test-run binds the workspace ws-extra which is not declared by test-pipeline",
+		"spec.workspaces: test-run does not bind the workspace ws-no-needed which test-pipeline requires",
+	})
+	assertion(t, ePR.ValidateTaskRunSpecs(&eP), []string{
+		"spec.taskRunSpecs: taskRunSpecs refers to the pipelineTask task-d which does not exist in test-pipeline, did you mean task-c?",
+	})
+
+	assertion(t, ePR.ValidateServiceAccounts(nil), nil)
+	lookup := func(ns, name string) error {
+		switch name {
+		case "builder":
+			return nil
+		case "deployer":
+			return apierrors.NewNotFound(schema.GroupResource{Resource: "serviceaccounts"}, name)
+		}
+		return errors.New("unexpected service account " + name)
+	}
+	assertion(t, ePR.ValidateServiceAccounts(lookup), []string{
+		"spec.taskRunSpecs: test-run uses the service account deployer which does not exist in team-a",
+	})
+}
+
+func TestPipelineFinder(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), testObject(t, yPipeline, "team-a"))
+	local := testPipeline(t, yResultsPipeline)
+
+	testCases := []struct {
+		name     string
+		run      string
+		client   bool
+		pipeline string
+		want     []string
+	}{
+		{name: "inline pipelineSpec", run: yInlinePipelineRun, pipeline: "inline-run"},
+		{name: "local pipeline", run: strings.Replace(yPipelineRun, "name: test-pipeline", "name: results-pipeline", 1), pipeline: "results-pipeline"},
+		{name: "cluster pipeline", run: yPipelineRun, client: true, pipeline: "test-pipeline"},
+		{name: "offline", run: yPipelineRun, want: []string{"spec.pipelineRef: test-run refers to the pipeline test-pipeline which is not in the files"}},
+		{
+			name:   "missing pipeline",
+			run:    strings.Replace(yPipelineRun, "name: test-pipeline", "name: other-pipeline", 1),
+			client: true,
+			want:   []string{"spec.pipelineRef: test-run refers to the pipeline other-pipeline which does not exist in team-a"},
+		},
+		{
+			name: "remote pipeline",
+			run:  strings.Replace(yPipelineRun, "name: test-pipeline", "resolver: git", 1),
+			want: []string{"spec.pipelineRef: test-run is not validated because its pipeline is resolved remotely"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			finder := pipelineFinder{local: []extendedPipeline{local}, cache: make(map[string]*extendedPipeline)}
+			if tc.client {
+				finder.client = client
+			}
+			ePR := testPipelineRun(t, tc.run)
+			p, diags := finder.pipeline(&ePR)
+			assertion(t, diags, tc.want)
+			if tc.pipeline == "" {
+				if p != nil {
+					t.Errorf("did not expect a pipeline but got %s", p.GetName())
+				}
+				return
+			}
+			if p == nil || p.GetName() != tc.pipeline {
+				t.Errorf("got %v but wanted the pipeline %s", p, tc.pipeline)
+			}
+		})
+	}
+}
+
+// pipelineRuns and their pipelines must be read from the files and the diagnostics located in them
+func TestValidatePipelineRunsOffline(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "runs.yaml"), yPipeline+"---\n"+yPipelineRun+"---\n"+yInlinePipelineRun)
+	set, err := loadManifests([]string{filepath.Join(dir, "runs.yaml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	err = validatePipelineRuns(&out, nil, set)
+	if got := exitCode(err); got != exitFindings {
+		t.Errorf("got exit code %d (%v) but wanted %d", got, err, exitFindings)
+	}
+	for _, want := range []string{
+		"runs.yaml:84:5: error: test-run does not supply the param param4 which test-pipeline requires [run-params]",
+		"runs.yaml:86:7: warning: test-run supplies the param param5 which is not declared by test-pipeline, did you mean param4? [run-params]",
+		"runs.yaml:89:5: error: test-run does not bind the workspace ws-no-needed which test-pipeline requires [run-workspaces]",
+		"runs.yaml:94:5: error: taskRunSpecs refers to the pipelineTask task-d which does not exist in test-pipeline, did you mean task-c? [task-run-specs]",
+		"runs.yaml:98:1: error: inline-run does not supply the param message which inline-run requires [run-params]",
+		"runs.yaml:98:1: error: inline-run does not bind the workspace source which inline-run requires [run-workspaces]",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("wanted %q in the output but got:\n%s", want, out.String())
+		}
+	}
+}
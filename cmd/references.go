@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// A $(tasks.x.results.y) style reference
+type resultReference struct {
+	pipelineTask string
+	result       string
+}
+
+// A $(params.x) style reference. index is either empty, [*] or [n] and key is only set for object params, e.g. $(params.x.key)
+type paramReference struct {
+	name  string
+	index string
+	key   string
+}
+
+var (
+	paramReferenceRegex     = regexp.MustCompile(`\$\(params(?:\.([A-Za-z0-9_-]+)|\[["']([^"']+)["']\])(\[\*\]|\[\d+\])?(?:\.([A-Za-z0-9_-]+))?\)`)
+	resultReferenceRegex    = regexp.MustCompile(`\$\(tasks\.([A-Za-z0-9_-]+)\.results(?:\.([A-Za-z0-9_-]+)|\[["']([^"']+)["']\])(?:\[\*\]|\[\d+\])?(?:\.[A-Za-z0-9_-]+)?\)`)
+	workspaceReferenceRegex = regexp.MustCompile(`\$\(workspaces\.([A-Za-z0-9_-]+)\.(?:path|bound|claim|volume)\)`)
+	taskResultRegex         = regexp.MustCompile(`\$\(results(?:\.([A-Za-z0-9_-]+)|\[["']([^"']+)["']\])\.path\)`)
+)
+
+// Returns all the $(params.x) references in the given string
+func paramReferences(s string) (refs []paramReference) {
+	for _, m := range paramReferenceRegex.FindAllStringSubmatch(s, -1) {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		refs = append(refs, paramReference{name: name, index: m[3], key: m[4]})
+	}
+	return
+}
+
+// Returns all the $(tasks.x.results.y) references in the given string
+func resultReferences(s string) (refs []resultReference) {
+	for _, m := range resultReferenceRegex.FindAllStringSubmatch(s, -1) {
+		result := m[2]
+		if result == "" {
+			result = m[3]
+		}
+		refs = append(refs, resultReference{pipelineTask: m[1], result: result})
+	}
+	return
+}
+
+// Returns the names of all the workspaces which are referenced by $(workspaces.x.path) and similar variables in the given string
+func workspaceReferences(s string) (names []string) {
+	for _, m := range workspaceReferenceRegex.FindAllStringSubmatch(s, -1) {
+		names = append(names, m[1])
+	}
+	return
+}
+
+// Returns the names of all the results of a task which are referenced by $(results.x.path) in the given string
+func taskResultReferences(s string) (names []string) {
+	for _, m := range taskResultRegex.FindAllStringSubmatch(s, -1) {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+// Returns all the string values in the given object, no matter how deeply they are nested.
+// The object is walked through its json representation, so the values of all the fields are included.
+func stringValues(object interface{}) (values []string) {
+	b, err := json.Marshal(object)
+	if err != nil {
+		return nil
+	}
+	var generic interface{}
+	if err = json.Unmarshal(b, &generic); err != nil {
+		return nil
+	}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch t := v.(type) {
+		case string:
+			values = append(values, t)
+		case []interface{}:
+			for _, i := range t {
+				walk(i)
+			}
+		case map[string]interface{}:
+			for _, i := range t {
+				walk(i)
+			}
+		}
+	}
+	walk(generic)
+	return
+}
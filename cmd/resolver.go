@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tknv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	// url of a git repository (or org/repo) to the directory of its local checkout, set by --git-checkout
+	gitCheckouts map[string]string
+	// directory with a local copy of the tekton catalog, set by --hub-catalog
+	hubCatalog string
+	// bundle image reference to the directory or file with the manifests of the bundle, set by --bundle
+	bundleDirs map[string]string
+)
+
+// Fetches the task that a taskRef refers to through a resolver. ns is the namespace of the pipeline.
+type taskResolver interface {
+	resolve(ns string, params map[string]string) (extendedTask, error)
+}
+
+// Resolvers keyed by the name that taskRef.resolver uses
+type resolvers map[string]taskResolver
+
+// Tasks that are fetched by a resolver, keyed by the remoteRefKey of the taskRef that they were resolved for.
+// They are kept apart from the tasks of the cluster, so that a resolved task only satisfies the taskRefs with
+// the same resolver params and never a taskRef that refers to a task by its name.
+type resolvedTasks map[string]extendedTask
+
+// Returns the resolvers that are configured through the flags. The cluster resolver reads the tasks through
+// the client, or from the given tasks when mario runs without a cluster.
+func newResolvers(client dynamic.Interface, catalog taskCatalog, tasks []extendedTask) resolvers {
+	return resolvers{
+		"git":     gitResolver{checkouts: gitCheckouts},
+		"cluster": clusterResolver{client: client, catalog: catalog, tasks: tasks},
+		"hub":     hubResolver{dir: hubCatalog},
+		"bundles": bundleResolver{bundles: bundleDirs},
+	}
+}
+
+// Returns true if the taskRef is resolved remotely, either through a resolver or a tekton bundle
+func isRemoteRef(ref *tknv1beta1.TaskRef) bool {
+	return ref != nil && (ref.Resolver != "" || ref.Bundle != "")
+}
+
+// Returns the name of the resolver and its params for a remote taskRef. The deprecated taskRef.bundle
+// is handled by the bundles resolver.
+func remoteRef(ref *tknv1beta1.TaskRef) (string, map[string]string) {
+	params := make(map[string]string)
+	if ref.Bundle != "" {
+		params["bundle"], params["name"], params["kind"] = ref.Bundle, ref.Name, "task"
+		return "bundles", params
+	}
+	for _, p := range ref.Params {
+		params[p.Name] = p.Value.StringVal
+	}
+	return string(ref.Resolver), params
+}
+
+// Returns the key that identifies a remote taskRef, e.g. git:pathInRepo=task.yaml,url=https://example.com/repo
+func remoteRefKey(ref *tknv1beta1.TaskRef) string {
+	resolver, params := remoteRef(ref)
+	var pairs []string
+	for k, v := range params {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return resolver + ":" + strings.Join(pairs, ",")
+}
+
+// Resolves the remote taskRefs of the pipeline. It returns the resolved tasks and the diagnostics of the
+// taskRefs that can not be resolved.
+func (r resolvers) resolvePipeline(p *extendedPipeline) (resolvedTasks, []diagnostic) {
+	resolved := make(resolvedTasks)
+	var diags []diagnostic
+	for _, pt := range allPipelineTasks(p) {
+		if !isRemoteRef(pt.TaskRef) {
+			continue
+		}
+		key := remoteRefKey(pt.TaskRef)
+		if _, ok := resolved[key]; ok {
+			continue
+		}
+		name, params := remoteRef(pt.TaskRef)
+		d := diagnostic{rule: ruleTaskRef, severity: severityError, location: location{pipelineTask: pt.Name}, subject: pt.TaskRef.Name}
+		if variables := paramVariables(params); len(variables) > 0 {
+			d.severity = severityWarning
+			d.message = fmt.Sprintf("%s is not validated because the params of the %s resolver use %s", pt.Name, name, strings.Join(variables, ", "))
+			diags = append(diags, d)
+			continue
+		}
+		resolver, ok := r[name]
+		if !ok {
+			d.message = fmt.Sprintf("%s uses the resolver %s which is not supported", pt.Name, name)
+			diags = append(diags, d)
+			continue
+		}
+		et, err := resolver.resolve(p.GetNamespace(), params)
+		if err != nil {
+			d.message = fmt.Sprintf("%s refers to a task which can not be resolved by the %s resolver: %s", pt.Name, name, err)
+			diags = append(diags, d)
+			continue
+		}
+		resolved[key] = et
+	}
+	return resolved, sortDiagnostics(diags)
+}
+
+// Returns the $(...) variables that are used by the resolver params, sorted
+func paramVariables(params map[string]string) (variables []string) {
+	for _, v := range params {
+		if strings.Contains(v, "$(") {
+			variables = append(variables, v)
+		}
+	}
+	sort.Strings(variables)
+	return
+}
+
+// Returns the value of a param that the resolver requires
+func requiredParam(params map[string]string, name string) (string, error) {
+	if params[name] == "" {
+		return "", fmt.Errorf("the param %s is required", name)
+	}
+	return params[name], nil
+}
+
+// Ensures that the kind param, which defaults to task, asks for a task
+func taskKind(params map[string]string) error {
+	if kind := params["kind"]; kind != "" && !strings.EqualFold(kind, "task") {
+		return fmt.Errorf("a taskRef can not refer to a %s", kind)
+	}
+	return nil
+}
+
+// Returns the task with the given name from the manifests in the path, or the only task if name is empty
+func taskInManifests(path, name string) (extendedTask, error) {
+	set, err := loadManifests([]string{path})
+	if err != nil {
+		return extendedTask{}, err
+	}
+	if name == "" && len(set.tasks) == 1 {
+		return set.tasks[0], nil
+	}
+	if t := findTask(set.tasks, name); name != "" && t != nil {
+		return *t, nil
+	}
+	if name == "" {
+		return extendedTask{}, fmt.Errorf("%s has %d tasks but must have exactly one", path, len(set.tasks))
+	}
+	return extendedTask{}, fmt.Errorf("%s does not have the task %s", path, name)
+}
+
+// Resolves the git resolver against local checkouts of the repositories. The files are read from the working
+// tree of the checkout, the revision param is not checked out.
+type gitResolver struct {
+	checkouts map[string]string
+}
+
+func (g gitResolver) resolve(ns string, params map[string]string) (extendedTask, error) {
+	repo := params["url"]
+	if repo == "" && params["org"] != "" && params["repo"] != "" {
+		repo = params["org"] + "/" + params["repo"]
+	}
+	if repo == "" {
+		return extendedTask{}, errors.New("the param url, or org and repo, is required")
+	}
+	path, err := requiredParam(params, "pathInRepo")
+	if err != nil {
+		return extendedTask{}, err
+	}
+	dir, ok := g.checkouts[repo]
+	if !ok {
+		return extendedTask{}, fmt.Errorf("there is no local checkout of %s, use --git-checkout %s=DIR", repo, repo)
+	}
+	return taskInManifests(filepath.Join(dir, filepath.FromSlash(path)), "")
+}
+
+// Resolves the cluster resolver. It reads the tasks of the namespaces in the catalog from there and gets the
+// other tasks through the client. Without a client, the tasks are looked up in the local tasks.
+type clusterResolver struct {
+	client  dynamic.Interface
+	catalog taskCatalog
+	tasks   []extendedTask
+}
+
+func (c clusterResolver) resolve(ns string, params map[string]string) (extendedTask, error) {
+	if err := taskKind(params); err != nil {
+		return extendedTask{}, err
+	}
+	name, err := requiredParam(params, "name")
+	if err != nil {
+		return extendedTask{}, err
+	}
+	if params["namespace"] != "" {
+		ns = params["namespace"]
+	}
+
+	if c.client == nil {
+		if t := findTask(c.tasks, name); t != nil {
+			return *t, nil
+		}
+		return extendedTask{}, fmt.Errorf("the task %s does not exist", name)
+	}
+	if _, listed := c.catalog[ns]; listed {
+		if t := c.catalog.task(ns, name); t != nil {
+			return *t, nil
+		}
+		return extendedTask{}, fmt.Errorf("the task %s does not exist in %s", name, ns)
+	}
+	u, err := c.client.Resource(tektonResource("tasks")).Namespace(ns).Get(context.TODO(), name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return extendedTask{}, fmt.Errorf("the task %s does not exist in %s", name, ns)
+	}
+	if err != nil {
+		return extendedTask{}, fmt.Errorf("getting the task %s in %s: %w", name, ns, err)
+	}
+	return toTask(u)
+}
+
+// Resolves the hub resolver against a local copy of the tekton catalog, which keeps every task in
+// task/<name>/<version>/<name>.yaml
+type hubResolver struct {
+	dir string
+}
+
+func (h hubResolver) resolve(ns string, params map[string]string) (extendedTask, error) {
+	if h.dir == "" {
+		return extendedTask{}, errors.New("there is no local catalog, use --hub-catalog DIR")
+	}
+	if err := taskKind(params); err != nil {
+		return extendedTask{}, err
+	}
+	name, err := requiredParam(params, "name")
+	if err != nil {
+		return extendedTask{}, err
+	}
+	version, err := requiredParam(params, "version")
+	if err != nil {
+		return extendedTask{}, err
+	}
+	return taskInManifests(filepath.Join(h.dir, "task", name, version, name+".yaml"), name)
+}
+
+// Resolves the bundles resolver, and taskRef.bundle, against local directories which hold the manifests of the bundles
+type bundleResolver struct {
+	bundles map[string]string
+}
+
+func (b bundleResolver) resolve(ns string, params map[string]string) (extendedTask, error) {
+	if err := taskKind(params); err != nil {
+		return extendedTask{}, err
+	}
+	bundle, err := requiredParam(params, "bundle")
+	if err != nil {
+		return extendedTask{}, err
+	}
+	name, err := requiredParam(params, "name")
+	if err != nil {
+		return extendedTask{}, err
+	}
+	dir, ok := b.bundles[bundle]
+	if !ok {
+		return extendedTask{}, fmt.Errorf("there is no local copy of %s, use --bundle %s=DIR", bundle, bundle)
+	}
+	return taskInManifests(dir, name)
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var yRemotePipeline = `apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: remote-pipeline
+spec:
+  params:
+    - name: revision
+  tasks:
+    - name: git-task
+      taskRef:
+        resolver: git
+        params:
+          - name: url
+            value: https://example.com/org/repo.git
+          - name: revision
+            value: main
+          - name: pathInRepo
+            value: tasks/build.yaml
+    - name: hub-task
+      taskRef:
+        resolver: hub
+        params:
+          - name: name
+            value: golang-build
+          - name: version
+            value: "0.3"
+      params:
+        - name: package
+          value: example.com/app
+    - name: bundle-task
+      taskRef:
+        name: lint
+        bundle: registry.example.com/tasks:1.0
+      params:
+        - name: unknown
+          value: some-value
+    - name: cluster-task
+      taskRef:
+        resolver: cluster
+        params:
+          - name: name
+            value: task-a
+          - name: namespace
+            value: shared
+      params:
+        - name: param1
+          value: $(params.revision)
+    - name: missing-checkout
+      taskRef:
+        resolver: git
+        params:
+          - name: url
+            value: https://example.com/other.git
+          - name: pathInRepo
+            value: task.yaml
+    - name: variable-revision
+      taskRef:
+        resolver: git
+        params:
+          - name: url
+            value: https://example.com/org/repo.git
+          - name: revision
+            value: $(params.revision)
+          - name: pathInRepo
+            value: tasks/build.yaml
+    - name: http-task
+      taskRef:
+        resolver: http
+        params:
+          - name: url
+            value: https://example.com/task.yaml
+    - name: plain-build
+      taskRef:
+        name: build
+`
+
+var yBuildTask = `apiVersion: tekton.dev/v1
+kind: Task
+metadata:
+  name: build
+spec:
+  params:
+    - name: image
+  steps:
+    - name: build
+      image: golang
+`
+
+var yHubTask = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: golang-build
+spec:
+  params:
+    - name: package
+  workspaces:
+    - name: source
+`
+
+var yBundle = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: lint
+---
+apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: test
+`
+
+// remote taskRefs must be fetched from the local stand-ins and validated like the tasks in the cluster.
+// The resolved tasks must not satisfy the taskRefs that refer to a task by its name.
+func TestResolvePipeline(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "repo", "tasks", "build.yaml"), yBuildTask)
+	writeFile(t, filepath.Join(dir, "catalog", "task", "golang-build", "0.3", "golang-build.yaml"), yHubTask)
+	writeFile(t, filepath.Join(dir, "bundle", "tasks.yaml"), yBundle)
+	localTask, err := toTask(testObject(t, yTasks, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := resolvers{
+		"git":     gitResolver{checkouts: map[string]string{"https://example.com/org/repo.git": filepath.Join(dir, "repo")}},
+		"cluster": clusterResolver{tasks: []extendedTask{localTask}},
+		"hub":     hubResolver{dir: filepath.Join(dir, "catalog")},
+		"bundles": bundleResolver{bundles: map[string]string{"registry.example.com/tasks:1.0": filepath.Join(dir, "bundle")}},
+	}
+	eP := testPipeline(t, yRemotePipeline)
+	result := newPipelineResult(&eP, source{}, nil, nil, r)
+	assertion(t, result.diagnostics, []string{
+		"bundle-task: bundle-task passes the param unknown which is not declared by lint",
+		"git-task: git-task is missing the param image",
+		"http-task: http-task uses the resolver http which is not supported",
+		"hub-task: hub-task needs the workspace source which is not declared in spec.workspaces",
+		"missing-checkout: missing-checkout refers to a task which can not be resolved by the git resolver: there is no local checkout of https://example.com/other.git, use --git-checkout https://example.com/other.git=DIR",
+		"plain-build: plain-build refers to the task build which is not found",
+		"variable-revision: variable-revision is not validated because the params of the git resolver use $(params.revision)",
+	})
+}
+
+// the cluster resolver must get the tasks of the namespaces that are not in the catalog
+func TestClusterResolver(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}: "TaskList",
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		testObject(t, yTaskFinally, "team-a"),
+		testObject(t, yHubTask, "shared"),
+	)
+	catalog := buildTaskCatalog(context.TODO(), client, []string{"team-a"})
+	r := clusterResolver{client: client, catalog: catalog}
+
+	testCases := []struct {
+		name    string
+		ns      string
+		params  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "task in the catalog", ns: "team-a", params: map[string]string{"name": "task-finally"}, want: "task-finally"},
+		{name: "task in another namespace", ns: "team-a", params: map[string]string{"name": "golang-build", "namespace": "shared"}, want: "golang-build"},
+		{name: "missing task in the catalog", ns: "team-a", params: map[string]string{"name": "golang-build"}, wantErr: true},
+		{name: "missing task in another namespace", ns: "team-a", params: map[string]string{"name": "task-finally", "namespace": "shared"}, wantErr: true},
+		{name: "pipeline kind", ns: "team-a", params: map[string]string{"name": "task-finally", "kind": "pipeline"}, wantErr: true},
+		{name: "missing name", ns: "team-a", params: map[string]string{}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			et, err := r.resolve(tc.ns, tc.params)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error but got %s", et.GetName())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("did not expect error but got: %s", err)
+			}
+			if et.GetName() != tc.want {
+				t.Errorf("got %s but wanted %s", et.GetName(), tc.want)
+			}
+		})
+	}
+}
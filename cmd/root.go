@@ -5,11 +5,11 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/util/homedir"
 )
 
 var kubeconfig string
@@ -27,15 +27,18 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	SilenceErrors: true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	var eErr *exitError
+	if err != nil && (!errors.As(err, &eErr) || eErr.err != nil) {
+		fmt.Fprintln(os.Stderr, "Error:", err)
 	}
+	os.Exit(exitCode(err))
 }
 
 func init() {
@@ -43,7 +46,10 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "k", filepath.Join(homedir.HomeDir(), ".kube", "config"), "Path to kubeconfig (default is $HOME/.kube/config)")
+	rootCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "k", "", "Path to kubeconfig (default is $KUBECONFIG, $HOME/.kube/config or the in-cluster config)")
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use")
+	rootCmd.PersistentFlags().StringVar(&kubeCluster, "cluster", "", "Name of the kubeconfig cluster to use")
+	rootCmd.PersistentFlags().StringVar(&kubeUser, "user", "", "Name of the kubeconfig user to use")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
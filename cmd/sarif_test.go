@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// every registered rule must be a rule of the run and results must point to the line of the offending part of the pipeline
+func TestPrintSARIF(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "pipeline.yaml")
+	writeFile(t, file, yPipeline)
+	set, err := loadManifests([]string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cTasks := []extendedTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
+	}
+	cClusterTasks := []extendedClusterTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+	}
+	eP := &set.pipelines[0]
+	results := []pipelineResult{newPipelineResult(eP, set.sources[0], cTasks, cClusterTasks, nil)}
+
+	var out bytes.Buffer
+	if err := printSARIF(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("output is not valid json: %s\n%s", err, out.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("wanted a SARIF 2.1.0 log with a single run but got %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(registeredRules) {
+		t.Errorf("got %d rules but wanted %d", len(run.Tool.Driver.Rules), len(registeredRules))
+	}
+
+	want := []sarifResult{
+		{
+			RuleID:    "task-ref",
+			RuleIndex: 0,
+			Level:     "error",
+			Message:   sarifMessage{Text: "test-pipeline: task-a refers to the task task-a which is not found"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)},
+				Region:           sarifRegion{StartLine: 26, StartColumn: 9},
+			}}},
+		},
+		{
+			RuleID:    "unused-params",
+			RuleIndex: 10,
+			Level:     "warning",
+			Message:   sarifMessage{Text: "test-pipeline: the param param-not-needed is not used by any pipelineTask"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)},
+				Region:           sarifRegion{StartLine: 22, StartColumn: 7},
+			}}},
+		},
+	}
+	for _, w := range want {
+		found := false
+		for _, r := range run.Results {
+			if r.RuleID == w.RuleID && r.Message == w.Message {
+				found = true
+				if r.RuleIndex != w.RuleIndex || r.Level != w.Level || len(r.Locations) != 1 || r.Locations[0] != w.Locations[0] {
+					t.Errorf("got %+v but wanted %+v", r, w)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("wanted result %+v but got %+v", w, run.Results)
+		}
+	}
+}
+
+// pipelines that are read from the cluster do not have a location
+func TestPrintSARIFWithoutSource(t *testing.T) {
+	tPipeline := testPipeline(t, yPipeline)
+	results := []pipelineResult{newPipelineResult(&tPipeline, source{}, nil, nil, nil)}
+
+	var out bytes.Buffer
+	if err := printSARIF(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Runs[0].Results) == 0 {
+		t.Fatal("wanted results but did not get any")
+	}
+	for _, r := range log.Runs[0].Results {
+		if len(r.Locations) != 0 {
+			t.Errorf("did not want a location but got %+v", r.Locations)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+)
+
+// Returns true if the pipeline matches --pipeline and --selector
+func selectsPipeline(eP *extendedPipeline, selector labels.Selector) bool {
+	if pipelineName != "" && eP.GetName() != pipelineName {
+		return false
+	}
+	return selector.Matches(labels.Set(eP.GetLabels()))
+}
+
+// Returns the pipelines of the set, and their sources, which match --pipeline and --selector
+func selectPipelines(set manifestSet) (manifestSet, error) {
+	selector, err := labels.Parse(pipelineSelector)
+	if err != nil {
+		return set, fmt.Errorf("parsing --selector: %w", err)
+	}
+	selected := set
+	selected.pipelines, selected.sources = nil, nil
+	for i := range set.pipelines {
+		if selectsPipeline(&set.pipelines[i], selector) {
+			selected.pipelines = append(selected.pipelines, set.pipelines[i])
+			selected.sources = append(selected.sources, set.sources[i])
+		}
+	}
+	return selected, nil
+}
+
+// Lists the pipelines in the namespace that match --pipeline and --selector. It lists the pipelines in all
+// the namespaces if ns is empty. A single pipeline in a namespace is fetched by its name so that it does
+// not need the permission to list the pipelines.
+func listPipelines(client dynamic.Interface, ns string) (*unstructured.UnstructuredList, error) {
+	selector, err := labels.Parse(pipelineSelector)
+	if err != nil {
+		return nil, usageError(fmt.Errorf("parsing --selector: %w", err))
+	}
+	list := &unstructured.UnstructuredList{}
+
+	if pipelineName != "" && ns != "" {
+		p, err := client.Resource(tektonResource("pipelines")).Namespace(ns).Get(context.TODO(), pipelineName, v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, usageError(fmt.Errorf("pipeline %s not found in %s", pipelineName, ns))
+		}
+		if err != nil {
+			return nil, clusterError(fmt.Errorf("getting pipeline %s in %s: %w", pipelineName, ns, err))
+		}
+		list.Items = append(list.Items, *p)
+	} else {
+		opts := v1.ListOptions{LabelSelector: selector.String()}
+		if pipelineName != "" {
+			opts.FieldSelector = "metadata.name=" + pipelineName
+		}
+		pipelines, err := listAll(context.TODO(), client.Resource(tektonResource("pipelines")).Namespace(ns), opts)
+		if err != nil {
+			return nil, clusterError(fmt.Errorf("listing pipelines: %w", err))
+		}
+		list.Items = pipelines.Items
+	}
+
+	// field and label selectors are not supported by every server, so they are checked again
+	selected := &unstructured.UnstructuredList{}
+	for _, p := range list.Items {
+		if (pipelineName == "" || p.GetName() == pipelineName) && selector.Matches(labels.Set(p.GetLabels())) {
+			selected.Items = append(selected.Items, p)
+		}
+	}
+	switch {
+	case pipelineName != "" && len(selected.Items) == 0 && pipelineSelector != "":
+		return nil, usageError(fmt.Errorf("pipeline %s does not match --selector %s", pipelineName, pipelineSelector))
+	case pipelineName != "" && len(selected.Items) == 0:
+		return nil, usageError(fmt.Errorf("pipeline %s not found", pipelineName))
+	}
+	return selected, nil
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Returns a fake client with the given objects which can not list anything outside of the team-a namespace
+func teamClient(t *testing.T, objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	t.Helper()
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}:    "PipelineList",
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}:        "TaskList",
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "clustertasks"}: "ClusterTaskList",
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	client.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "team-a" {
+			return true, nil, forbidden(action.GetResource().Resource)
+		}
+		return false, nil, nil
+	})
+	return client
+}
+
+// Returns a forbidden error as the api server does
+func forbidden(resource string) error {
+	return apierrors.NewForbidden(schema.GroupResource{Group: "tekton.dev", Resource: resource}, "", errors.New("no cluster-wide permissions"))
+}
+
+func TestListPipelines(t *testing.T) {
+	labelled := testObject(t, yResultsPipeline, "team-a")
+	labelled.SetLabels(map[string]string{"team": "a"})
+	client := teamClient(t, testObject(t, yPipeline, "team-a"), labelled, testObject(t, yPipeline, "team-b"))
+
+	defer func(name, selector string) { pipelineName, pipelineSelector = name, selector }(pipelineName, pipelineSelector)
+	testCases := []struct {
+		name     string
+		ns       string
+		pipeline string
+		selector string
+		want     []string
+		code     int
+	}{
+		{name: "namespace", ns: "team-a", want: []string{"results-pipeline", "test-pipeline"}},
+		{name: "selector", ns: "team-a", selector: "team=a", want: []string{"results-pipeline"}},
+		{name: "named pipeline", ns: "team-a", pipeline: "test-pipeline", want: []string{"test-pipeline"}},
+		{name: "named pipeline not matching the selector", ns: "team-a", pipeline: "test-pipeline", selector: "team=a", code: exitUsage},
+		{name: "missing pipeline", ns: "team-a", pipeline: "other-pipeline", code: exitUsage},
+		{name: "invalid selector", ns: "team-a", selector: "team==a=", code: exitUsage},
+		{name: "all namespaces", ns: "", code: exitCluster},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pipelineName, pipelineSelector = tc.pipeline, tc.selector
+			list, err := listPipelines(client, tc.ns)
+			if tc.code != exitOK {
+				if got := exitCode(err); got != tc.code {
+					t.Fatalf("got exit code %d (%v) but wanted %d", got, err, tc.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("did not expect error but got: %s", err)
+			}
+			var got []string
+			for _, p := range list.Items {
+				got = append(got, p.GetName())
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("got %v but wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectPipelines(t *testing.T) {
+	dir := t.TempDir()
+	labelled := strings.Replace(yResultsPipeline, "  name: results-pipeline\n", "  name: results-pipeline\n  labels:\n    team: a\n", 1)
+	writeFile(t, filepath.Join(dir, "pipelines.yaml"), yPipeline+"\n---\n"+labelled)
+	set, err := loadManifests([]string{filepath.Join(dir, "pipelines.yaml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(name, selector string) { pipelineName, pipelineSelector = name, selector }(pipelineName, pipelineSelector)
+	for _, tc := range []struct{ pipeline, selector, want string }{
+		{"", "", "test-pipeline,results-pipeline"},
+		{"", "team=a", "results-pipeline"},
+		{"", "team!=a", "test-pipeline"},
+		{"test-pipeline", "", "test-pipeline"},
+		{"test-pipeline", "team=a", ""},
+	} {
+		pipelineName, pipelineSelector = tc.pipeline, tc.selector
+		selected, err := selectPipelines(set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for i, eP := range selected.pipelines {
+			if selected.sources[i].file == "" {
+				t.Errorf("%s lost its source", eP.GetName())
+			}
+			got = append(got, eP.GetName())
+		}
+		if strings.Join(got, ",") != tc.want {
+			t.Errorf("--pipeline %q --selector %q: got %v but wanted %s", tc.pipeline, tc.selector, got, tc.want)
+		}
+	}
+}
+
+// a team without cluster-wide permissions must be able to validate its own namespace
+func TestValidateClusterNamespace(t *testing.T) {
+	client := teamClient(t, testObject(t, yPipeline, "team-a"), testObject(t, yPipeline, "team-b"))
+
+	defer func(files []string) { pipelineFiles = files }(pipelineFiles)
+	pipelineFiles = nil
+
+	var out strings.Builder
+	err := validateCluster(&out, client, manifestSet{}, "team-a")
+	if got := exitCode(err); got != exitFindings {
+		t.Errorf("got exit code %d (%v) but wanted %d", got, err, exitFindings)
+	}
+	if !strings.Contains(out.String(), "team-a/test-pipeline: error: task-a refers to the task task-a which is not found [task-ref]") {
+		t.Errorf("wanted team-a/test-pipeline to be validated but got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "clustertasks.tekton.dev is forbidden") {
+		t.Errorf("wanted the forbidden clustertasks to be reported but got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "team-b") {
+		t.Errorf("did not want team-b to be validated but got:\n%s", out.String())
+	}
+}
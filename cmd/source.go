@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Where an object was read from. node is the yaml document of the object which keeps the positions
+// of its fields. Objects that are read from the cluster have an empty source.
+type source struct {
+	file string
+	node *yamlv3.Node
+}
+
+// A position in a file. Lines and columns start at 1.
+type position struct {
+	line   int
+	column int
+}
+
+// Returns the position of a diagnostic in the source. It points to the param, workspace, etc. that the
+// subject of the diagnostic names, within the pipelineTask of the diagnostic or the field of the pipeline
+// (e.g. spec.params) when the diagnostic is not specific to a pipelineTask. It falls back to the
+// pipelineTask, the field and then the start of the document. ok is false if the source is empty.
+func (s source) diagnosticPosition(d diagnostic) (pos position, ok bool) {
+	if s.node == nil || len(s.node.Content) == 0 {
+		return position{}, false
+	}
+	root := s.node.Content[0]
+	if d.location.pipelineTask != "" {
+		if n := pipelineTaskNode(root, d.location.pipelineTask); n != nil {
+			if sn := subjectNode(n, d.subject, "params", "matrix.params", "workspaces", "taskRef"); sn != nil {
+				return nodePosition(sn), true
+			}
+			return nodePosition(n), true
+		}
+	}
+	if d.location.field != "" {
+		if n := fieldNode(root, d.location.field); n != nil {
+			if sn := subjectNode(n, d.subject, ""); sn != nil {
+				return nodePosition(sn), true
+			}
+			return nodePosition(n), true
+		}
+	}
+	return nodePosition(root), true
+}
+
+// Sets the file, line and column of the diagnostics. It does nothing for the objects that are not read from files.
+// Diagnostics which are located already, e.g. in an object that is embedded in the document, are kept.
+func (s source) locate(diags []diagnostic) {
+	if s.file == "" {
+		return
+	}
+	for i := range diags {
+		if diags[i].location.line > 0 {
+			continue
+		}
+		if pos, ok := s.diagnosticPosition(diags[i]); ok {
+			diags[i].location.file, diags[i].location.line, diags[i].location.column = s.file, pos.line, pos.column
+		}
+	}
+}
+
+// Returns the source of the i-th item of the list at the dotted path, e.g. spec.resourcetemplates, so that the
+// diagnostics of an object which is embedded in the document are located relative to it. The node of the
+// returned source is empty if the item does not exist.
+func (s source) item(path string, i int) source {
+	sub := source{file: s.file}
+	if s.node == nil || len(s.node.Content) == 0 {
+		return sub
+	}
+	if list := fieldNode(s.node.Content[0], path); list != nil && list.Kind == yamlv3.SequenceNode && i < len(list.Content) {
+		sub.node = &yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{list.Content[i]}}
+	}
+	return sub
+}
+
+// Returns the location of the start of the document. Only the file is set if the document is not known.
+func (s source) rootLocation() location {
+	l := location{file: s.file}
+	if s.node != nil && len(s.node.Content) > 0 {
+		l.line, l.column = s.node.Content[0].Line, s.node.Content[0].Column
+	}
+	return l
+}
+
+// Returns the position of the node
+func nodePosition(n *yamlv3.Node) position {
+	return position{line: n.Line, column: n.Column}
+}
+
+// Returns the item which is named after the subject in the first of the given lists (dotted paths
+// relative to n, an empty path is n itself) which has it. A mapping, e.g. taskRef, matches if its name is the subject.
+// The workspaces of a pipelineTask are named after the pipeline workspace that they bind, which is their workspace
+// field or their name if they do not have one.
+func subjectNode(n *yamlv3.Node, subject string, paths ...string) *yamlv3.Node {
+	if subject == "" {
+		return nil
+	}
+	for _, path := range paths {
+		list := n
+		if path != "" {
+			list = fieldNode(n, path)
+		}
+		if list == nil {
+			continue
+		}
+		switch list.Kind {
+		case yamlv3.SequenceNode:
+			for _, item := range list.Content {
+				name := mappingValue(item, "name")
+				if ws := mappingValue(item, "workspace"); path == "workspaces" && ws != nil {
+					name = ws
+				}
+				if name != nil && name.Value == subject {
+					return item
+				}
+			}
+		case yamlv3.MappingNode:
+			if name := mappingValue(list, "name"); name != nil && name.Value == subject {
+				return list
+			}
+		}
+	}
+	return nil
+}
+
+// Returns where the diagnostic is as file:line:column. Diagnostics of the objects that are read from
+// the cluster are located by namespace/name of the object.
+func (r pipelineResult) where(d diagnostic) string {
+	if d.location.file != "" {
+		return fmt.Sprintf("%s:%d:%d", d.location.file, d.location.line, d.location.column)
+	}
+	return r.subject.id()
+}
+
+// Returns the node of the pipelineTask with the given name in spec.tasks or spec.finally
+func pipelineTaskNode(root *yamlv3.Node, name string) *yamlv3.Node {
+	for _, field := range []string{"spec.tasks", "spec.finally"} {
+		list := fieldNode(root, field)
+		if list == nil || list.Kind != yamlv3.SequenceNode {
+			continue
+		}
+		for _, item := range list.Content {
+			if n := mappingValue(item, "name"); n != nil && n.Value == name {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the value node of a dotted path of mapping keys, e.g. spec.params
+func fieldNode(root *yamlv3.Node, path string) *yamlv3.Node {
+	n := root
+	start := 0
+	for i := 0; i <= len(path); i++ {
+		if i < len(path) && path[i] != '.' {
+			continue
+		}
+		if n = mappingValue(n, path[start:i]); n == nil {
+			return nil
+		}
+		start = i + 1
+	}
+	return n
+}
+
+// Returns the value node of the given key in a mapping node, or nil if the key does not exist
+func mappingValue(n *yamlv3.Node, key string) *yamlv3.Node {
+	if n == nil || n.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// diagnostics must point to the part of the pipeline that their subject names, falling back to the
+// pipelineTask, the field and the start of the document
+func TestFindingPosition(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pipeline.yaml")
+	writeFile(t, file, "# leading comment\n"+yPipeline)
+	set, err := loadManifests([]string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := set.sources[0]
+
+	testCases := []struct {
+		name       string
+		diagnostic diagnostic
+		want       position
+	}{
+		{name: "param of a pipelineTask", diagnostic: diagnostic{location: location{pipelineTask: "task-a"}, subject: "param-extra"}, want: position{line: 33, column: 11}},
+		{name: "workspace of a pipelineTask", diagnostic: diagnostic{location: location{pipelineTask: "task-b"}, subject: "ws1"}, want: position{line: 47, column: 11}},
+		{name: "workspace of a pipelineTask by its binding", diagnostic: diagnostic{location: location{pipelineTask: "task-a"}, subject: "ws2"}, want: position{line: 40, column: 11}},
+		{name: "task side name of a bound workspace", diagnostic: diagnostic{location: location{pipelineTask: "task-a"}, subject: "ws-a-2"}, want: position{line: 25, column: 7}},
+		{name: "taskRef of a pipelineTask", diagnostic: diagnostic{location: location{pipelineTask: "task-b"}, subject: "task-b"}, want: position{line: 44, column: 9}},
+		{name: "pipelineTask", diagnostic: diagnostic{location: location{pipelineTask: "task-c"}, subject: "unknown"}, want: position{line: 52, column: 7}},
+		{name: "param of the pipeline", diagnostic: diagnostic{location: location{field: "spec.params"}, subject: "param4"}, want: position{line: 21, column: 7}},
+		{name: "field of the pipeline", diagnostic: diagnostic{location: location{field: "spec.workspaces"}}, want: position{line: 9, column: 5}},
+		{name: "document", diagnostic: diagnostic{location: location{pipelineTask: "not-in-the-pipeline"}}, want: position{line: 3, column: 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := src.diagnosticPosition(tc.diagnostic)
+			if !ok || got != tc.want {
+				t.Errorf("got %+v but wanted %+v", got, tc.want)
+			}
+		})
+	}
+
+	if _, ok := (source{}).diagnosticPosition(diagnostic{location: location{pipelineTask: "task-a"}}); ok {
+		t.Errorf("did not want a position for an empty source")
+	}
+}
+
+// the text output must print every diagnostic as file:line:column: severity: message [rule]
+func TestPrintErrorsPositions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pipeline.yaml")
+	writeFile(t, file, yPipeline)
+	set, err := loadManifests([]string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cTasks := []extendedTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
+	}
+	cClusterTasks := []extendedClusterTask{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+	}
+	eP := &set.pipelines[0]
+
+	var out bytes.Buffer
+	printErrors(&out, newPipelineResult(eP, set.sources[0], cTasks, cClusterTasks, nil))
+	want := file + ":26:9: error: task-a refers to the task task-a which is not found [task-ref]\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("wanted %q in the output but got:\n%s", want, out.String())
+	}
+
+	out.Reset()
+	tPipeline := testPipeline(t, yPipeline)
+	printErrors(&out, newPipelineResult(&tPipeline, source{}, cTasks, cClusterTasks, nil))
+	want = "test-pipeline: error: task-a refers to the task task-a which is not found [task-ref]\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("wanted %q in the output but got:\n%s", want, out.String())
+	}
+}
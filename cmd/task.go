@@ -0,0 +1,434 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	tknv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// A validation of a task or clusterTask on its own. ClusterTasks are validated as tasks with the kind ClusterTask.
+type taskValidation struct {
+	rule  string
+	check func(t *extendedTask) []diagnostic
+}
+
+// The validations of the tasks and clusterTasks, in the order that they run
+var taskValidations = []taskValidation{
+	{
+		rule:  ruleTaskParams,
+		check: (*extendedTask).ValidateTaskParams,
+	},
+	{
+		rule:  ruleTaskWorkspaces,
+		check: (*extendedTask).ValidateTaskWorkspaces,
+	},
+	{
+		rule:  ruleTaskResults,
+		check: (*extendedTask).ValidateTaskResults,
+	},
+	{
+		rule:  ruleStepNames,
+		check: (*extendedTask).ValidateStepNames,
+	},
+	{
+		rule:  ruleParamTypes,
+		check: (*extendedTask).ValidateParamDefaults,
+	},
+}
+
+var taskCmd = &cobra.Command{
+	Use:   "task [NAME...]",
+	Short: "Validates the tasks and clusterTasks on their own",
+	Long: `Validates the tasks and clusterTasks that are read from --pipeline-file, or the
+	tasks with the given names in the cluster, without a pipeline that uses them.
+	It ensures the following:
+	- $(params.x), $(workspaces.x.path) and $(results.x.path) references must be
+	  declared by the task
+	- params, workspaces and results that the task declares should be used by its steps
+	- steps must have unique names
+	- the defaults of the params must match their declared types
+
+	Without names or files, all the tasks in the namespace and the clusterTasks that
+	match --selector are validated. Names are looked up as tasks in the namespace and
+	then as clusterTasks.`,
+	RunE: validateRunE(func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && allNamespaces {
+			return usageError(errors.New("task names can not be used with --all-namespaces"))
+		}
+		return nil
+	}, func(cmd *cobra.Command, args []string) error {
+		if len(pipelineFiles) > 0 {
+			set, err := loadManifests(pipelineFiles)
+			if err != nil {
+				return usageError(err)
+			}
+			tasks, sources, err := selectTasks(set, args)
+			if err != nil {
+				return usageError(err)
+			}
+			if len(tasks) == 0 {
+				return usageError(fmt.Errorf("no tasks found in %v", pipelineFiles))
+			}
+			return validateTasks(cmd.OutOrStdout(), tasks, sources, set.invalid)
+		}
+
+		client, ns, err := connect()
+		if err != nil {
+			return err
+		}
+		if allNamespaces {
+			ns = ""
+		}
+		tasks, invalid, err := listTasks(client, ns, args)
+		if err != nil {
+			return err
+		}
+		return validateTasks(cmd.OutOrStdout(), tasks, make([]source, len(tasks)), invalid)
+	}),
+}
+
+func init() {
+	validateCmd.AddCommand(taskCmd)
+}
+
+// Returns the clusterTask as a task which keeps the kind ClusterTask, so that both go through the same validations
+func clusterTaskAsTask(ect extendedClusterTask) extendedTask {
+	return extendedTask{TypeMeta: ect.TypeMeta, ObjectMeta: ect.ObjectMeta, Spec: ect.Spec}
+}
+
+// Returns the tasks and clusterTasks of the set, and their sources, which match --selector and have one of the given names
+func selectTasks(set manifestSet, names []string) (tasks []extendedTask, sources []source, err error) {
+	selector, err := labels.Parse(pipelineSelector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing --selector: %w", err)
+	}
+	all, allSources := append([]extendedTask{}, set.tasks...), append([]source{}, set.taskSources...)
+	for i, ect := range set.clusterTasks {
+		all = append(all, clusterTaskAsTask(ect))
+		allSources = append(allSources, set.ctSources[i])
+	}
+	for i, et := range all {
+		if (len(names) == 0 || sliceIncludeString(names, et.GetName())) && selector.Matches(labels.Set(et.GetLabels())) {
+			tasks = append(tasks, et)
+			sources = append(sources, allSources[i])
+		}
+	}
+	return tasks, sources, nil
+}
+
+// Gets the tasks, or the clusterTasks, with the given names or lists the tasks in the namespace (all namespaces
+// if ns is empty) and the clusterTasks that match --selector when no name is given. ClusterTasks that can not be
+// listed, e.g. because they are cluster scoped, are returned as invalid objects.
+func listTasks(client dynamic.Interface, ns string, names []string) (tasks []extendedTask, invalid []invalidObject, err error) {
+	list := &unstructured.UnstructuredList{}
+	if len(names) == 0 {
+		if list, err = listAll(context.TODO(), client.Resource(tektonResource("tasks")).Namespace(ns), v1.ListOptions{LabelSelector: pipelineSelector}); err != nil {
+			return nil, nil, clusterError(fmt.Errorf("listing tasks: %w", err))
+		}
+		clusterTasks, err := listAll(context.TODO(), client.Resource(tektonResource("clustertasks")), v1.ListOptions{LabelSelector: pipelineSelector})
+		switch {
+		case apierrors.IsForbidden(err):
+			invalid = append(invalid, invalidObject{kind: "ClusterTask", name: "clustertasks", err: fmt.Errorf("listing clustertasks: %w", err)})
+		case err != nil:
+			return nil, nil, clusterError(fmt.Errorf("listing clustertasks: %w", err))
+		default:
+			list.Items = append(list.Items, clusterTasks.Items...)
+		}
+	}
+	for _, name := range names {
+		u, err := client.Resource(tektonResource("tasks")).Namespace(ns).Get(context.TODO(), name, v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			u, err = client.Resource(tektonResource("clustertasks")).Get(context.TODO(), name, v1.GetOptions{})
+		}
+		if apierrors.IsNotFound(err) {
+			return nil, nil, usageError(fmt.Errorf("task %s not found in %s", name, ns))
+		}
+		if err != nil {
+			return nil, nil, clusterError(fmt.Errorf("getting task %s in %s: %w", name, ns, err))
+		}
+		list.Items = append(list.Items, *u)
+	}
+
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.GetKind() == "ClusterTask" {
+			var eCT extendedClusterTask
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &eCT); err != nil {
+				invalid = append(invalid, newInvalidObject(item, err))
+				continue
+			}
+			tasks = append(tasks, clusterTaskAsTask(eCT))
+			continue
+		}
+		eT, err := toTask(item)
+		if err != nil {
+			invalid = append(invalid, newInvalidObject(item, err))
+			continue
+		}
+		tasks = append(tasks, eT)
+	}
+	return tasks, invalid, nil
+}
+
+// Validates the tasks on their own and prints the results along with the objects that could not be read
+func validateTasks(w io.Writer, tasks []extendedTask, sources []source, invalid []invalidObject) error {
+	var results []pipelineResult
+	for i := range tasks {
+		results = append(results, newTaskResult(&tasks[i], sources[i]))
+	}
+	results = append(results, invalidResults(invalid)...)
+	if err := printResults(w, results); err != nil {
+		return fmt.Errorf("printing the results: %w", err)
+	}
+	return resultsError(results)
+}
+
+// Validates the task and locates the diagnostics in the source that the task was read from
+func newTaskResult(et *extendedTask, src source) pipelineResult {
+	var diags []diagnostic
+	var rules []string
+	for _, v := range taskValidations {
+		rules = append(rules, v.rule)
+		diags = append(diags, v.check(et)...)
+	}
+	src.locate(diags)
+	kind := et.Kind
+	if kind == "" {
+		kind = "Task"
+	}
+	return pipelineResult{subject: newSubject(kind, et.ObjectMeta, src), diagnostics: sortDiagnostics(diags), rules: rules}
+}
+
+// A part of the task spec that the steps use, e.g. spec.steps, with its string values
+type taskField struct {
+	field  string
+	values []string
+}
+
+// Returns the parts of the task spec where params, workspaces and results are used: the steps, the sidecars,
+// the stepTemplate and the volumes
+func taskFields(et *extendedTask) []taskField {
+	return []taskField{
+		{field: "spec.steps", values: stringValues(et.Spec.Steps)},
+		{field: "spec.sidecars", values: stringValues(et.Spec.Sidecars)},
+		{field: "spec.stepTemplate", values: stringValues(et.Spec.StepTemplate)},
+		{field: "spec.volumes", values: stringValues(et.Spec.Volumes)},
+	}
+}
+
+// Ensures that every $(params.x) which the steps, sidecars, stepTemplate or volumes of the task reference is declared
+// in spec.params of the task, and that every declared param is referenced.
+func (et *extendedTask) ValidateTaskParams() []diagnostic {
+	var declared, used []string
+	var diags []diagnostic
+	for _, param := range et.Spec.Params {
+		declared = append(declared, param.Name)
+	}
+	for _, f := range taskFields(et) {
+		for _, v := range f.values {
+			for _, ref := range paramReferences(v) {
+				used = append(used, ref.name)
+				if sliceIncludeString(declared, ref.name) {
+					continue
+				}
+				d := diagnostic{
+					rule:     ruleTaskParams,
+					severity: severityError,
+					location: location{field: f.field},
+					subject:  ref.name,
+					message:  fmt.Sprintf("%s references $(params.%s) which is not declared in spec.params", et.GetName(), ref.name),
+				}
+				if suggestion := closestString(ref.name, declared); suggestion != "" {
+					d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+				}
+				if !includesDiagnostic(diags, d) {
+					diags = append(diags, d)
+				}
+			}
+		}
+	}
+	for _, name := range declared {
+		if !sliceIncludeString(used, name) {
+			diags = append(diags, diagnostic{
+				rule:     ruleTaskParams,
+				severity: severityWarning,
+				location: location{field: "spec.params"},
+				subject:  name,
+				message:  fmt.Sprintf("the param %s is not used by the steps of %s", name, et.GetName()),
+			})
+		}
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that the workspaces which the steps and sidecars of the task use, through $(workspaces.x.path) and similar
+// variables or their workspaces field, are declared in spec.workspaces of the task, and that every declared workspace
+// is used. Workspaces are also considered used when their mount path appears in the task, e.g. /workspace/source.
+func (et *extendedTask) ValidateTaskWorkspaces() []diagnostic {
+	var declared, used []string
+	var diags []diagnostic
+	for _, w := range et.Spec.Workspaces {
+		declared = append(declared, w.Name)
+	}
+	undeclared := func(name, field, message string) {
+		d := diagnostic{rule: ruleTaskWorkspaces, severity: severityError, location: location{field: field}, subject: name, message: message}
+		if suggestion := closestString(name, declared); suggestion != "" {
+			d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+		}
+		if !includesDiagnostic(diags, d) {
+			diags = append(diags, d)
+		}
+	}
+
+	var usages []tknv1beta1.WorkspaceUsage
+	for _, s := range et.Spec.Steps {
+		usages = append(usages, s.Workspaces...)
+	}
+	for _, s := range et.Spec.Sidecars {
+		usages = append(usages, s.Workspaces...)
+	}
+	for _, u := range usages {
+		used = append(used, u.Name)
+		if !sliceIncludeString(declared, u.Name) {
+			undeclared(u.Name, "spec.steps", fmt.Sprintf("%s mounts the workspace %s which is not declared in spec.workspaces", et.GetName(), u.Name))
+		}
+	}
+	for _, f := range taskFields(et) {
+		for _, v := range f.values {
+			for _, name := range workspaceReferences(v) {
+				used = append(used, name)
+				if !sliceIncludeString(declared, name) {
+					undeclared(name, f.field, fmt.Sprintf("%s references $(workspaces.%s) which is not declared in spec.workspaces", et.GetName(), name))
+				}
+			}
+		}
+	}
+
+	for _, w := range et.Spec.Workspaces {
+		mountPath := w.MountPath
+		if mountPath == "" {
+			mountPath = "/workspace/" + w.Name
+		}
+		if sliceIncludeString(used, w.Name) || taskMentions(et, mountPath) {
+			continue
+		}
+		diags = append(diags, diagnostic{
+			rule:     ruleTaskWorkspaces,
+			severity: severityWarning,
+			location: location{field: "spec.workspaces"},
+			subject:  w.Name,
+			message:  fmt.Sprintf("the workspace %s is not used by the steps of %s", w.Name, et.GetName()),
+		})
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that every $(results.x.path) which the task references is declared in spec.results of the task, and
+// that every declared result is written. Results are also considered written when their file appears in the
+// task, e.g. /tekton/results/digest.
+func (et *extendedTask) ValidateTaskResults() []diagnostic {
+	var declared, used []string
+	var diags []diagnostic
+	for _, r := range et.Spec.Results {
+		declared = append(declared, r.Name)
+	}
+	for _, f := range taskFields(et) {
+		for _, v := range f.values {
+			for _, name := range taskResultReferences(v) {
+				used = append(used, name)
+				if sliceIncludeString(declared, name) {
+					continue
+				}
+				d := diagnostic{
+					rule:     ruleTaskResults,
+					severity: severityError,
+					location: location{field: f.field},
+					subject:  name,
+					message:  fmt.Sprintf("%s references $(results.%s.path) which is not declared in spec.results", et.GetName(), name),
+				}
+				if suggestion := closestString(name, declared); suggestion != "" {
+					d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+				}
+				if !includesDiagnostic(diags, d) {
+					diags = append(diags, d)
+				}
+			}
+		}
+	}
+	for _, name := range declared {
+		if sliceIncludeString(used, name) || taskMentions(et, "/tekton/results/"+name) {
+			continue
+		}
+		diags = append(diags, diagnostic{
+			rule:     ruleTaskResults,
+			severity: severityWarning,
+			location: location{field: "spec.results"},
+			subject:  name,
+			message:  fmt.Sprintf("the result %s is not written by the steps of %s", name, et.GetName()),
+		})
+	}
+	return sortDiagnostics(diags)
+}
+
+// Returns true if any of the parts of the task that the steps use contains s
+func taskMentions(et *extendedTask, s string) bool {
+	for _, f := range taskFields(et) {
+		for _, v := range f.values {
+			if strings.Contains(v, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Ensures that the steps of the task have unique names. Steps without a name are not considered.
+func (et *extendedTask) ValidateStepNames() []diagnostic {
+	var names []string
+	seen := make(map[string]int)
+	for _, s := range et.Spec.Steps {
+		if s.Name == "" {
+			continue
+		}
+		if seen[s.Name] == 0 {
+			names = append(names, s.Name)
+		}
+		seen[s.Name]++
+	}
+	var diags []diagnostic
+	for _, name := range names {
+		if seen[name] > 1 {
+			diags = append(diags, diagnostic{
+				rule:     ruleStepNames,
+				severity: severityError,
+				location: location{field: "spec.steps"},
+				subject:  name,
+				message:  fmt.Sprintf("the name %s is used by %d steps of %s", name, seen[name], et.GetName()),
+			})
+		}
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that the defaults in spec.params of the task match their declared types
+func (et *extendedTask) ValidateParamDefaults() []diagnostic {
+	var diags []diagnostic
+	for _, param := range et.Spec.Params {
+		for _, d := range defaultTypeErrors(param) {
+			d.location = location{field: "spec.params"}
+			diags = append(diags, d)
+		}
+	}
+	return sortDiagnostics(diags)
+}
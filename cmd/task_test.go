@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var yLintTask = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: build
+spec:
+  params:
+    - name: image
+    - name: context
+      default: .
+    - name: unused
+    - name: flags
+      type: array
+      default: "--verbose"
+  workspaces:
+    - name: source
+    - name: cache
+    - name: dockerconfig
+      mountPath: /kaniko/.docker
+    - name: unused-ws
+  results:
+    - name: digest
+    - name: url
+    - name: unused-result
+  steps:
+    - name: build
+      image: gcr.io/kaniko-project/executor
+      workingDir: $(workspaces.source.path)
+      args:
+        - --destination=$(params.image)
+        - --context=$(params.contxt)
+        - --digest-file=$(results.digest.path)
+        - --cache-dir=$(workspaces.cach.path)
+        - --docker-config=/kaniko/.docker
+    - name: build
+      image: alpine
+      script: echo $(params.flags[*]) > /tekton/results/url
+      workspaces:
+        - name: cache
+        - name: secrets
+    - name: report
+      image: alpine
+      script: cat $(results.digst.path)
+`
+
+// Returns the typed task of the yaml
+func testTask(t *testing.T, y string) extendedTask {
+	t.Helper()
+	eT, err := toTask(testObject(t, y, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return eT
+}
+
+func TestValidateTask(t *testing.T) {
+	eT := testTask(t, yLintTask)
+
+	assertion(t, eT.ValidateTaskParams(), []string{
+		"spec.params: the param context is not used by the steps of build",
+		"spec.params: the param unused is not used by the steps of build",
+		"spec.steps: build references $(params.contxt) which is not declared in spec.params, did you mean context?",
+	})
+	assertion(t, eT.ValidateTaskWorkspaces(), []string{
+		"spec.steps: build references $(workspaces.cach) which is not declared in spec.workspaces, did you mean cache?",
+		"spec.steps: build mounts the workspace secrets which is not declared in spec.workspaces",
+		"spec.workspaces: the workspace unused-ws is not used by the steps of build",
+	})
+	assertion(t, eT.ValidateTaskResults(), []string{
+		"spec.results: the result unused-result is not written by the steps of build",
+		"spec.steps: build references $(results.digst.path) which is not declared in spec.results, did you mean digest?",
+	})
+	assertion(t, eT.ValidateStepNames(), []string{
+		"spec.steps: the name build is used by 2 steps of build",
+	})
+	assertion(t, eT.ValidateParamDefaults(), []string{
+		"spec.params: flags is an array param but its default is a string",
+	})
+}
+
+// Tasks and clusterTasks must be read from the files and the diagnostics located in them
+func TestValidateTasksOffline(t *testing.T) {
+	dir := t.TempDir()
+	clusterTask := strings.Replace(strings.Replace(yLintTask, "kind: Task", "kind: ClusterTask", 1), "name: build\nspec", "name: cluster-build\nspec", 1)
+	writeFile(t, filepath.Join(dir, "tasks.yaml"), yLintTask+"---\n"+clusterTask)
+	set, err := loadManifests([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks, sources, err := selectTasks(set, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	err = validateTasks(&out, tasks, sources, set.invalid)
+	if got := exitCode(err); got != exitFindings {
+		t.Errorf("got exit code %d (%v) but wanted %d", got, err, exitFindings)
+	}
+	for _, want := range []string{
+		"tasks.yaml:10:7: warning: the param unused is not used by the steps of build [task-params]",
+		"tasks.yaml:11:7: error: flags is an array param but its default is a string [param-types]",
+		"tasks.yaml:25:5: error: build references $(params.contxt) which is not declared in spec.params, did you mean context? [task-params]",
+		"tasks.yaml:25:7: error: the name build is used by 2 steps of build [step-names]",
+		"tasks.yaml:68:7: error: the name build is used by 2 steps of cluster-build [step-names]",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("wanted %q in the output but got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestListTasks(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}:        "TaskList",
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "clustertasks"}: "ClusterTaskList",
+	}
+	clusterTask := testObject(t, yLintTask, "")
+	clusterTask.SetKind("ClusterTask")
+	clusterTask.SetName("cluster-build")
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		testObject(t, yLintTask, "team-a"), testObject(t, yTaskFinally, "team-b"), clusterTask)
+
+	testCases := []struct {
+		name  string
+		names []string
+		want  []string
+		code  int
+	}{
+		{name: "namespace", want: []string{"build", "cluster-build"}},
+		{name: "named task", names: []string{"build"}, want: []string{"build"}},
+		{name: "named clusterTask", names: []string{"cluster-build"}, want: []string{"cluster-build"}},
+		{name: "missing task", names: []string{"other"}, code: exitUsage},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tasks, invalid, err := listTasks(client, "team-a", tc.names)
+			if got := exitCode(err); got != tc.code {
+				t.Fatalf("got exit code %d (%v) but wanted %d", got, err, tc.code)
+			}
+			var got []string
+			for _, et := range tasks {
+				got = append(got, et.GetName())
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") || len(invalid) > 0 {
+				t.Errorf("got the tasks %v (invalid %v) but wanted %v", got, invalid, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,608 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/cobra"
+	yamlv3 "gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	triggersGroup = "triggers.tekton.dev"
+	// version of triggers.tekton.dev that is used to read the objects from the cluster. The fields that
+	// mario reads are the same in v1alpha1 and v1beta1, so the objects of the files can use either.
+	triggersVersion = "v1beta1"
+)
+
+// A $(tt.params.x) reference in the resourcetemplates of a TriggerTemplate
+var templateParamReferenceRegex = regexp.MustCompile(`\$\(tt\.params(?:\.([A-Za-z0-9_-]+)|\[["']([^"']+)["']\])\)`)
+
+// The fields of the tekton triggers objects that mario validates. They are declared here instead of importing
+// tekton triggers, the fields that mario does not use are ignored.
+type (
+	triggerTemplate struct {
+		v1.TypeMeta   `json:",inline"`
+		v1.ObjectMeta `json:"metadata,omitempty"`
+		Spec          triggerTemplateSpec `json:"spec"`
+	}
+	triggerTemplateSpec struct {
+		Params            []triggerParamSpec       `json:"params,omitempty"`
+		ResourceTemplates []map[string]interface{} `json:"resourcetemplates,omitempty"`
+	}
+	triggerParamSpec struct {
+		Name    string  `json:"name"`
+		Default *string `json:"default,omitempty"`
+	}
+
+	// TriggerBindings and ClusterTriggerBindings
+	triggerBinding struct {
+		v1.TypeMeta   `json:",inline"`
+		v1.ObjectMeta `json:"metadata,omitempty"`
+		Spec          triggerBindingSpec `json:"spec"`
+	}
+	triggerBindingSpec struct {
+		Params []triggerParam `json:"params,omitempty"`
+	}
+	triggerParam struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	eventListener struct {
+		v1.TypeMeta   `json:",inline"`
+		v1.ObjectMeta `json:"metadata,omitempty"`
+		Spec          eventListenerSpec `json:"spec"`
+	}
+	eventListenerSpec struct {
+		Triggers []eventListenerTrigger `json:"triggers,omitempty"`
+	}
+	// A trigger of an EventListener, which either refers to a Trigger or declares its bindings and template
+	eventListenerTrigger struct {
+		Name       string               `json:"name,omitempty"`
+		TriggerRef string               `json:"triggerRef,omitempty"`
+		Bindings   []triggerSpecBinding `json:"bindings,omitempty"`
+		Template   *triggerSpecTemplate `json:"template,omitempty"`
+	}
+
+	trigger struct {
+		v1.TypeMeta   `json:",inline"`
+		v1.ObjectMeta `json:"metadata,omitempty"`
+		Spec          triggerSpec `json:"spec"`
+	}
+	triggerSpec struct {
+		Bindings []triggerSpecBinding `json:"bindings,omitempty"`
+		Template *triggerSpecTemplate `json:"template,omitempty"`
+	}
+	// A binding of a trigger. It either refers to a TriggerBinding (or a ClusterTriggerBinding when kind says so)
+	// or binds a single param by name and value.
+	triggerSpecBinding struct {
+		Name  string  `json:"name,omitempty"`
+		Value *string `json:"value,omitempty"`
+		Ref   string  `json:"ref,omitempty"`
+		Kind  string  `json:"kind,omitempty"`
+	}
+	// The template of a trigger. name is the deprecated form of ref.
+	triggerSpecTemplate struct {
+		Ref  *string `json:"ref,omitempty"`
+		Name string  `json:"name,omitempty"`
+	}
+)
+
+// The rules that the TriggerTemplates are checked against. The pipelineRuns of their resourcetemplates are also
+// checked against pipelineRunRules.
+var templateRules = []string{ruleTemplateParams}
+
+// The rules that the EventListeners and Triggers are checked against
+var triggerRules = []string{ruleTriggerRefs, ruleBindingParams}
+
+var triggersCmd = &cobra.Command{
+	Use:   "triggers",
+	Short: "Validates the TriggerTemplates and the triggers that use them",
+	Args:  cobra.NoArgs,
+	Long: `Validates the tekton triggers objects that are read from --pipeline-file, or
+	the ones in the namespace, so that the errors do not wait for a webhook to surface.
+	It ensures the following:
+	- $(tt.params.x) references in resourcetemplates must refer to the params of the
+	  TriggerTemplate and the params of the TriggerTemplate must be used
+	- pipelineRuns in resourcetemplates must satisfy their pipelines, the same way as
+	  validate pipelinerun checks them
+	- triggers of EventListeners and Triggers must refer to TriggerTemplates,
+	  TriggerBindings, ClusterTriggerBindings and Triggers that exist
+	- the bindings of a trigger must supply the params of its TriggerTemplate that
+	  don't have a default value
+
+	With --pipeline-file, the TriggerBindings and the TriggerTemplates that the triggers
+	refer to must be in the files. The pipelines are looked up in --pipeline-file first
+	and then in the cluster. With --offline the cluster is not used at all.
+
+	--selector keeps the TriggerTemplates, EventListeners and Triggers that are
+	validated, their references are looked up in all the objects.`,
+	RunE: validateRunE(func(cmd *cobra.Command, args []string) error {
+		if offline && len(pipelineFiles) == 0 {
+			return usageError(errors.New("--pipeline-file must be provided with --offline"))
+		}
+		return nil
+	}, func(cmd *cobra.Command, args []string) error {
+		selector, err := labels.Parse(pipelineSelector)
+		if err != nil {
+			return usageError(fmt.Errorf("parsing --selector: %w", err))
+		}
+		var set manifestSet
+		if len(pipelineFiles) > 0 {
+			if set, err = loadManifests(pipelineFiles); err != nil {
+				return usageError(err)
+			}
+			if set.countTriggers(selector) == 0 {
+				return usageError(fmt.Errorf("no TriggerTemplates, EventListeners or Triggers found in %v", pipelineFiles))
+			}
+		}
+		if offline {
+			return validateTriggers(cmd.OutOrStdout(), nil, set, selector)
+		}
+
+		client, ns, err := connect()
+		if err != nil {
+			return err
+		}
+		if len(pipelineFiles) > 0 {
+			set.defaultTriggersNamespace(ns)
+		} else {
+			if allNamespaces {
+				ns = ""
+			}
+			if set, err = listTriggers(client, ns); err != nil {
+				return err
+			}
+		}
+		return validateTriggers(cmd.OutOrStdout(), client, set, selector)
+	}),
+}
+
+func init() {
+	validateCmd.AddCommand(triggersCmd)
+
+	triggersCmd.Flags().BoolVar(&offline, "offline", false, "Validate the pipelineRuns of the TriggerTemplates against the pipelines in --pipeline-file only, without a cluster")
+}
+
+// Returns the GroupVersionResource that is used to read the given triggers.tekton.dev resource from the cluster
+func triggersResource(resource string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: triggersGroup, Version: triggersVersion, Resource: resource}
+}
+
+// Adds the tekton triggers object to the set. Objects of any other kind are ignored.
+// It returns an error if the object can not be converted.
+func (set *manifestSet) addTriggerObject(u *unstructured.Unstructured, src source) error {
+	if u.GroupVersionKind().Group != triggersGroup {
+		return nil
+	}
+	switch u.GetKind() {
+	case "TriggerTemplate":
+		var tt triggerTemplate
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &tt); err != nil {
+			return err
+		}
+		set.triggerTemplates = append(set.triggerTemplates, tt)
+		set.templateSources = append(set.templateSources, src)
+	case "TriggerBinding", "ClusterTriggerBinding":
+		var tb triggerBinding
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &tb); err != nil {
+			return err
+		}
+		set.triggerBindings = append(set.triggerBindings, tb)
+	case "EventListener":
+		var el eventListener
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &el); err != nil {
+			return err
+		}
+		set.eventListeners = append(set.eventListeners, el)
+		set.listenerSources = append(set.listenerSources, src)
+	case "Trigger":
+		var t trigger
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &t); err != nil {
+			return err
+		}
+		set.triggers = append(set.triggers, t)
+		set.triggerSources = append(set.triggerSources, src)
+	}
+	return nil
+}
+
+// Returns the number of TriggerTemplates, EventListeners and Triggers in the set that match the selector
+func (set manifestSet) countTriggers(selector labels.Selector) (n int) {
+	for _, tt := range set.triggerTemplates {
+		if selector.Matches(labels.Set(tt.GetLabels())) {
+			n++
+		}
+	}
+	for _, el := range set.eventListeners {
+		if selector.Matches(labels.Set(el.GetLabels())) {
+			n++
+		}
+	}
+	for _, t := range set.triggers {
+		if selector.Matches(labels.Set(t.GetLabels())) {
+			n++
+		}
+	}
+	return
+}
+
+// Sets the namespace of the tekton triggers objects that do not have one. ClusterTriggerBindings are not namespaced.
+func (set *manifestSet) defaultTriggersNamespace(ns string) {
+	for i := range set.triggerTemplates {
+		if set.triggerTemplates[i].GetNamespace() == "" {
+			set.triggerTemplates[i].SetNamespace(ns)
+		}
+	}
+	for i := range set.triggerBindings {
+		if set.triggerBindings[i].GetNamespace() == "" && set.triggerBindings[i].Kind != "ClusterTriggerBinding" {
+			set.triggerBindings[i].SetNamespace(ns)
+		}
+	}
+	for i := range set.eventListeners {
+		if set.eventListeners[i].GetNamespace() == "" {
+			set.eventListeners[i].SetNamespace(ns)
+		}
+	}
+	for i := range set.triggers {
+		if set.triggers[i].GetNamespace() == "" {
+			set.triggers[i].SetNamespace(ns)
+		}
+	}
+}
+
+// Lists the tekton triggers objects in the namespace, all namespaces if ns is empty. They are listed without
+// --selector, since the objects that match it may refer to the ones that do not. ClusterTriggerBindings that can
+// not be listed, because they are cluster scoped, are reported as unreadable and do not stop the validation.
+func listTriggers(client dynamic.Interface, ns string) (set manifestSet, err error) {
+	for _, resource := range []string{"triggertemplates", "triggerbindings", "clustertriggerbindings", "eventlisteners", "triggers"} {
+		var ri dynamic.ResourceInterface = client.Resource(triggersResource(resource))
+		if resource != "clustertriggerbindings" {
+			ri = client.Resource(triggersResource(resource)).Namespace(ns)
+		}
+		list, err := listAll(context.TODO(), ri, v1.ListOptions{})
+		if resource == "clustertriggerbindings" && apierrors.IsForbidden(err) {
+			set.invalid = append(set.invalid, invalidObject{kind: "ClusterTriggerBinding", name: resource, err: fmt.Errorf("listing %s: %w", resource, err)})
+			set.unknownClusterBindings = true
+			continue
+		}
+		if err != nil {
+			return set, clusterError(fmt.Errorf("listing %s: %w", resource, err))
+		}
+		for i := range list.Items {
+			if err := set.addTriggerObject(&list.Items[i], source{}); err != nil {
+				set.invalid = append(set.invalid, newInvalidObject(&list.Items[i], err))
+			}
+		}
+	}
+	return set, nil
+}
+
+// Validates the TriggerTemplates, with the pipelineRuns of their resourcetemplates, and the EventListeners and
+// Triggers that match the selector. The references of the triggers are looked up in the whole set. The pipelines
+// are looked up in the set and then in the cluster, the client is nil when mario runs without a cluster.
+func validateTriggers(w io.Writer, client dynamic.Interface, set manifestSet, selector labels.Selector) error {
+	finder := pipelineFinder{local: set.pipelines, client: client, cache: make(map[string]*extendedPipeline)}
+	lookup := newServiceAccountLookup(client)
+
+	var results []pipelineResult
+	for i := range set.triggerTemplates {
+		if !selector.Matches(labels.Set(set.triggerTemplates[i].GetLabels())) {
+			continue
+		}
+		results = append(results, newTemplateResult(&set.triggerTemplates[i], set.templateSources[i], finder, lookup))
+	}
+	for i := range set.eventListeners {
+		el := &set.eventListeners[i]
+		if !selector.Matches(labels.Set(el.GetLabels())) {
+			continue
+		}
+		var diags []diagnostic
+		for j, t := range el.Spec.Triggers {
+			name := t.Name
+			if name == "" {
+				name = fmt.Sprintf("%s.triggers[%d]", el.GetName(), j)
+			}
+			var tDiags []diagnostic
+			if t.TriggerRef != "" {
+				tDiags = set.triggerRefErrors(name, el.GetNamespace(), t.TriggerRef)
+			} else {
+				tDiags = set.ValidateTrigger(name, el.GetNamespace(), t.Bindings, t.Template)
+			}
+			listenerTriggerSource(set.listenerSources[i], j).locate(tDiags)
+			for k := range tDiags {
+				tDiags[k].location.field = "spec.triggers"
+			}
+			diags = append(diags, tDiags...)
+		}
+		results = append(results, newTriggerResult(el.TypeMeta, el.ObjectMeta, set.listenerSources[i], diags))
+	}
+	for i := range set.triggers {
+		t := &set.triggers[i]
+		if !selector.Matches(labels.Set(t.GetLabels())) {
+			continue
+		}
+		diags := set.ValidateTrigger(t.GetName(), t.GetNamespace(), t.Spec.Bindings, t.Spec.Template)
+		results = append(results, newTriggerResult(t.TypeMeta, t.ObjectMeta, set.triggerSources[i], diags))
+	}
+	results = append(results, invalidResults(set.invalid)...)
+	if err := printResults(w, results); err != nil {
+		return fmt.Errorf("printing the results: %w", err)
+	}
+	return resultsError(results)
+}
+
+// Validates the TriggerTemplate and the pipelineRuns of its resourcetemplates. The diagnostics of the pipelineRuns
+// are located in their item of spec.resourcetemplates.
+func newTemplateResult(tt *triggerTemplate, src source, finder pipelineFinder, lookup serviceAccountLookup) pipelineResult {
+	diags := tt.ValidateTemplateParams(src)
+	for i, rt := range tt.Spec.ResourceTemplates {
+		u := &unstructured.Unstructured{Object: rt}
+		if u.GetKind() != "PipelineRun" || u.GroupVersionKind().Group != tektonGroup {
+			continue
+		}
+		var runDiags []diagnostic
+		pr, err := toPipelineRun(u)
+		switch {
+		case err != nil:
+			runDiags = append(runDiags, diagnostic{
+				rule:     ruleUnreadable,
+				severity: severityError,
+				subject:  embeddedRunName(u, i),
+				message:  fmt.Sprintf("the pipelineRun %s of %s can not be converted: %s", embeddedRunName(u, i), tt.GetName(), err),
+			})
+		default:
+			pr.SetName(embeddedRunName(u, i))
+			if pr.GetNamespace() == "" {
+				pr.SetNamespace(tt.GetNamespace())
+			}
+			p, pDiags := finder.pipeline(&pr)
+			runDiags = append(append(runDiags, pDiags...), runPipelineRunChecks(&pr, p, lookup)...)
+		}
+		src.item("spec.resourcetemplates", i).locate(runDiags)
+		diags = append(diags, runDiags...)
+	}
+	src.locate(diags)
+	return pipelineResult{subject: newSubject(tt.Kind, tt.ObjectMeta, src), diagnostics: sortDiagnostics(diags), rules: append(append([]string{}, templateRules...), pipelineRunRules()...)}
+}
+
+// Returns the name of the i-th resourcetemplate, its generateName or resourcetemplates[i] if it has neither
+func embeddedRunName(u *unstructured.Unstructured, i int) string {
+	switch {
+	case u.GetName() != "":
+		return u.GetName()
+	case u.GetGenerateName() != "":
+		return u.GetGenerateName()
+	}
+	return fmt.Sprintf("resourcetemplates[%d]", i)
+}
+
+// Returns the source of the j-th trigger of an EventListener, shaped like a Trigger object so that the
+// diagnostics of ValidateTrigger are located in it
+func listenerTriggerSource(src source, j int) source {
+	sub := src.item("spec.triggers", j)
+	if sub.node != nil {
+		item := sub.node.Content[0]
+		spec := &yamlv3.Node{Kind: yamlv3.MappingNode, Line: item.Line, Column: item.Column, Content: []*yamlv3.Node{{Kind: yamlv3.ScalarNode, Value: "spec"}, item}}
+		sub.node = &yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{spec}}
+	}
+	return sub
+}
+
+// Returns the result of an EventListener or Trigger, with the diagnostics located in its source
+func newTriggerResult(typeMeta v1.TypeMeta, objectMeta v1.ObjectMeta, src source, diags []diagnostic) pipelineResult {
+	src.locate(diags)
+	return pipelineResult{subject: newSubject(typeMeta.Kind, objectMeta, src), diagnostics: sortDiagnostics(diags), rules: triggerRules}
+}
+
+// Returns the names of all the params which are referenced by $(tt.params.x) in the given string
+func templateParamReferences(s string) (names []string) {
+	for _, m := range templateParamReferenceRegex.FindAllStringSubmatch(s, -1) {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+// Ensures that the $(tt.params.x) references in the resourcetemplates refer to the params of the TriggerTemplate
+// and that all of its params are used. The undeclared references are reported once for each resourcetemplate that
+// makes them, located in it through the source of the TriggerTemplate.
+func (tt *triggerTemplate) ValidateTemplateParams(src source) []diagnostic {
+	var declared, used []string
+	var diags []diagnostic
+	for _, param := range tt.Spec.Params {
+		declared = append(declared, param.Name)
+	}
+	for i, rt := range tt.Spec.ResourceTemplates {
+		var rtDiags []diagnostic
+		var reported []string
+		for _, v := range stringValues(rt) {
+			for _, name := range templateParamReferences(v) {
+				if !sliceIncludeString(used, name) {
+					used = append(used, name)
+				}
+				if sliceIncludeString(declared, name) || sliceIncludeString(reported, name) {
+					continue
+				}
+				reported = append(reported, name)
+				d := diagnostic{
+					rule:     ruleTemplateParams,
+					severity: severityError,
+					location: location{field: "spec.resourcetemplates"},
+					subject:  name,
+					message:  fmt.Sprintf("$(tt.params.%s) is used but %s is not declared by %s", name, name, tt.GetName()),
+				}
+				if suggestion := closestString(name, declared); suggestion != "" {
+					d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+				}
+				rtDiags = append(rtDiags, d)
+			}
+		}
+		src.item("spec.resourcetemplates", i).locate(rtDiags)
+		diags = append(diags, rtDiags...)
+	}
+	for _, name := range declared {
+		if !sliceIncludeString(used, name) {
+			diags = append(diags, diagnostic{
+				rule:     ruleTemplateParams,
+				severity: severityWarning,
+				location: location{field: "spec.params"},
+				subject:  name,
+				message:  fmt.Sprintf("the param %s is declared by %s but is not used by its resourcetemplates", name, tt.GetName()),
+			})
+		}
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that the trigger, of an EventListener or a Trigger object, refers to a TriggerTemplate and bindings that
+// exist, and that the bindings supply the params of the TriggerTemplate which do not have a default. Params that
+// the TriggerTemplate does not declare are reported as warnings. The diagnostics are located in the Trigger object,
+// the caller relocates them for EventListeners.
+func (set manifestSet) ValidateTrigger(name, ns string, bindings []triggerSpecBinding, template *triggerSpecTemplate) []diagnostic {
+	var diags []diagnostic
+	var bound []string
+	bindingsFound := true
+	for _, b := range bindings {
+		if b.Ref == "" {
+			bound = append(bound, b.Name)
+			continue
+		}
+		kind := b.Kind
+		if kind == "" {
+			kind = "TriggerBinding"
+		}
+		tb := set.findTriggerBinding(kind, ns, b.Ref)
+		if tb == nil && kind == "ClusterTriggerBinding" && set.unknownClusterBindings {
+			bindingsFound = false
+			continue
+		}
+		if tb == nil {
+			bindingsFound = false
+			diags = append(diags, diagnostic{
+				rule:     ruleTriggerRefs,
+				severity: severityError,
+				location: location{field: "spec.bindings"},
+				subject:  b.Ref,
+				message:  fmt.Sprintf("%s refers to the %s %s which does not exist", name, kind, b.Ref),
+			})
+			continue
+		}
+		for _, param := range tb.Spec.Params {
+			bound = append(bound, param.Name)
+		}
+	}
+
+	templateName := ""
+	if template != nil {
+		templateName = template.Name
+		if template.Ref != nil {
+			templateName = *template.Ref
+		}
+	}
+	d := diagnostic{rule: ruleTriggerRefs, severity: severityError, location: location{field: "spec.template"}, subject: templateName}
+	if templateName == "" {
+		d.message = fmt.Sprintf("%s does not refer to a TriggerTemplate", name)
+		return sortDiagnostics(append(diags, d))
+	}
+	tt := set.findTriggerTemplate(ns, templateName)
+	if tt == nil {
+		d.message = fmt.Sprintf("%s refers to the TriggerTemplate %s which does not exist", name, templateName)
+		return sortDiagnostics(append(diags, d))
+	}
+
+	var declared []string
+	for _, param := range tt.Spec.Params {
+		declared = append(declared, param.Name)
+		// the params of the missing bindings are not known, so only the bound params are checked
+		if param.Default == nil && bindingsFound && !sliceIncludeString(bound, param.Name) {
+			diags = append(diags, diagnostic{
+				rule:     ruleBindingParams,
+				severity: severityError,
+				location: location{field: "spec.bindings"},
+				subject:  param.Name,
+				message:  fmt.Sprintf("%s does not bind the param %s which the TriggerTemplate %s requires", name, param.Name, templateName),
+			})
+		}
+	}
+	sort.Strings(bound)
+	for _, param := range uniqueStrings(bound) {
+		if sliceIncludeString(declared, param) {
+			continue
+		}
+		d := diagnostic{
+			rule:     ruleBindingParams,
+			severity: severityWarning,
+			location: location{field: "spec.bindings"},
+			subject:  param,
+			message:  fmt.Sprintf("%s binds the param %s which is not declared by the TriggerTemplate %s", name, param, templateName),
+		}
+		if suggestion := closestString(param, declared); suggestion != "" {
+			d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+		}
+		diags = append(diags, d)
+	}
+	return sortDiagnostics(diags)
+}
+
+// Returns the diagnostics of an EventListener trigger whose triggerRef refers to a Trigger which does not exist
+func (set manifestSet) triggerRefErrors(name, ns, ref string) []diagnostic {
+	for _, t := range set.triggers {
+		if t.GetName() == ref && sameNamespace(t.GetNamespace(), ns) {
+			return nil
+		}
+	}
+	return []diagnostic{{
+		rule:     ruleTriggerRefs,
+		severity: severityError,
+		location: location{field: "spec.triggers"},
+		subject:  ref,
+		message:  fmt.Sprintf("%s refers to the Trigger %s which does not exist", name, ref),
+	}}
+}
+
+// Returns the TriggerTemplate with the given name in the namespace or nil if there is no such TriggerTemplate
+func (set manifestSet) findTriggerTemplate(ns, name string) *triggerTemplate {
+	for i := range set.triggerTemplates {
+		if tt := &set.triggerTemplates[i]; tt.GetName() == name && sameNamespace(tt.GetNamespace(), ns) {
+			return tt
+		}
+	}
+	return nil
+}
+
+// Returns the TriggerBinding or ClusterTriggerBinding with the given name or nil if there is no such binding.
+// TriggerBindings must be in the namespace.
+func (set manifestSet) findTriggerBinding(kind, ns, name string) *triggerBinding {
+	for i := range set.triggerBindings {
+		tb := &set.triggerBindings[i]
+		if tb.Kind != kind || tb.GetName() != name {
+			continue
+		}
+		if kind == "ClusterTriggerBinding" || sameNamespace(tb.GetNamespace(), ns) {
+			return tb
+		}
+	}
+	return nil
+}
+
+// Returns true if the namespaces are the same. An empty namespace, of an object that is read from a file
+// without a cluster, matches any namespace.
+func sameNamespace(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var yBuildPipeline = `apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: build-pipeline
+spec:
+  params:
+    - name: url
+    - name: revision
+    - name: verbose
+      default: "false"
+  workspaces:
+    - name: source
+  tasks:
+    - name: clone
+      taskSpec:
+        steps:
+          - image: alpine/git
+            script: git clone $(params.url) --branch $(params.revision) $(workspaces.source.path)
+      workspaces:
+        - name: source
+          workspace: source
+`
+
+var yTriggerTemplate = `apiVersion: triggers.tekton.dev/v1beta1
+kind: TriggerTemplate
+metadata:
+  name: build-template
+spec:
+  params:
+    - name: gitrevision
+    - name: gitrepositoryurl
+    - name: message
+      default: none
+    - name: unused
+      default: nothing
+  resourcetemplates:
+    - apiVersion: tekton.dev/v1beta1
+      kind: PipelineRun
+      metadata:
+        generateName: build-run-
+      spec:
+        pipelineRef:
+          name: build-pipeline
+        serviceAccountName: $(tt.params.account)
+        params:
+          - name: url
+            value: $(tt.params.gitrepositoryurl)
+          - name: revison
+            value: $(tt.params.gitrevison)
+        workspaces:
+          - name: source
+            emptyDir: {}
+    - apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: build-message
+      data:
+        message: $(tt.params.message)
+`
+
+var yTriggerBinding = `apiVersion: triggers.tekton.dev/v1beta1
+kind: TriggerBinding
+metadata:
+  name: push-binding
+spec:
+  params:
+    - name: gitrevision
+      value: $(body.head_commit.id)
+    - name: gitrepositoryur
+      value: $(body.repository.url)
+`
+
+var yEventListener = `apiVersion: triggers.tekton.dev/v1beta1
+kind: EventListener
+metadata:
+  name: listener
+spec:
+  triggers:
+    - name: push
+      bindings:
+        - ref: push-binding
+        - name: message
+          value: pushed
+      template:
+        ref: build-template
+    - name: pull-request
+      bindings:
+        - ref: pr-binding
+      template:
+        ref: build-template
+    - name: tag
+      template:
+        ref: tag-template
+    - triggerRef: release
+`
+
+var yTrigger = `apiVersion: triggers.tekton.dev/v1beta1
+kind: Trigger
+metadata:
+  name: manual
+spec:
+  bindings:
+    - kind: ClusterTriggerBinding
+      ref: manual-binding
+  template:
+    ref: build-template
+`
+
+var yClusterTriggerBinding = `apiVersion: triggers.tekton.dev/v1beta1
+kind: ClusterTriggerBinding
+metadata:
+  name: manual-binding
+spec:
+  params:
+    - name: gitrevision
+      value: main
+    - name: gitrepositoryurl
+      value: https://example.com/repo
+`
+
+// Returns the set of the tekton triggers objects in the yaml documents
+func testTriggerSet(t *testing.T, docs ...string) manifestSet {
+	t.Helper()
+	var set manifestSet
+	for _, y := range docs {
+		if err := set.addTriggerObject(testObject(t, y, ""), source{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return set
+}
+
+func TestValidateTemplateParams(t *testing.T) {
+	set := testTriggerSet(t, yTriggerTemplate)
+
+	assertion(t, set.triggerTemplates[0].ValidateTemplateParams(source{}), []string{
+		"spec.params: the param gitrevision is declared by build-template but is not used by its resourcetemplates",
+		"spec.params: the param unused is declared by build-template but is not used by its resourcetemplates",
+		"spec.resourcetemplates: $(tt.params.account) is used but account is not declared by build-template",
+		"spec.resourcetemplates: $(tt.params.gitrevison) is used but gitrevison is not declared by build-template, did you mean gitrevision?",
+	})
+}
+
+// an undeclared param must be reported in every resourcetemplate that uses it
+func TestValidateTemplateParamsEachResourceTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "template.yaml"), `apiVersion: triggers.tekton.dev/v1beta1
+kind: TriggerTemplate
+metadata:
+  name: account-template
+spec:
+  resourcetemplates:
+    - apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: first
+      data:
+        account: $(tt.params.account)
+        again: $(tt.params.account)
+    - apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: second
+      data:
+        account: $(tt.params.account)
+`)
+	set, err := loadManifests([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags := set.triggerTemplates[0].ValidateTemplateParams(set.templateSources[0])
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%d:%d %s", d.location.line, d.location.column, d.subject))
+	}
+	want := []string{"7:7 account", "14:7 account"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v but wanted %v", got, want)
+	}
+}
+
+func TestValidateTrigger(t *testing.T) {
+	set := testTriggerSet(t, yTriggerTemplate, yTriggerBinding, yClusterTriggerBinding)
+	listener := testTriggerSet(t, yEventListener).eventListeners[0]
+	manual := testTriggerSet(t, yTrigger).triggers[0]
+	ref := func(name string) *triggerSpecTemplate { return &triggerSpecTemplate{Ref: &name} }
+
+	testCases := []struct {
+		name     string
+		bindings []triggerSpecBinding
+		template *triggerSpecTemplate
+		want     []string
+	}{
+		{
+			name:     "binding and inline params",
+			bindings: listener.Spec.Triggers[0].Bindings,
+			template: listener.Spec.Triggers[0].Template,
+			want: []string{
+				"spec.bindings: binding and inline params binds the param gitrepositoryur which is not declared by the TriggerTemplate build-template, did you mean gitrepositoryurl?",
+				"spec.bindings: binding and inline params does not bind the param gitrepositoryurl which the TriggerTemplate build-template requires",
+			},
+		},
+		{
+			name:     "missing binding",
+			bindings: listener.Spec.Triggers[1].Bindings,
+			template: listener.Spec.Triggers[1].Template,
+			want:     []string{"spec.bindings: missing binding refers to the TriggerBinding pr-binding which does not exist"},
+		},
+		{
+			name:     "missing template",
+			template: listener.Spec.Triggers[2].Template,
+			want:     []string{"spec.template: missing template refers to the TriggerTemplate tag-template which does not exist"},
+		},
+		{name: "no template", want: []string{"spec.template: no template does not refer to a TriggerTemplate"}},
+		{name: "cluster binding", bindings: manual.Spec.Bindings, template: manual.Spec.Template},
+		{
+			name:     "deprecated template name",
+			bindings: []triggerSpecBinding{{Kind: "ClusterTriggerBinding", Ref: "manual-binding"}},
+			template: &triggerSpecTemplate{Name: "build-template"},
+		},
+		{
+			name:     "binding of another kind",
+			bindings: []triggerSpecBinding{{Kind: "ClusterTriggerBinding", Ref: "push-binding"}},
+			template: ref("build-template"),
+			want:     []string{"spec.bindings: binding of another kind refers to the ClusterTriggerBinding push-binding which does not exist"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertion(t, set.ValidateTrigger(tc.name, "team-a", tc.bindings, tc.template), tc.want)
+		})
+	}
+}
+
+// TriggerTemplates, their pipelineRuns and the triggers must be read from the files and the diagnostics located in them
+func TestValidateTriggersOffline(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pipeline.yaml"), yBuildPipeline)
+	writeFile(t, filepath.Join(dir, "triggers.yaml"), strings.Join([]string{yTriggerTemplate, yTriggerBinding, yEventListener, yTrigger, yClusterTriggerBinding}, "---\n"))
+	set, err := loadManifests([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	err = validateTriggers(&out, nil, set, labels.Everything())
+	if got := exitCode(err); got != exitFindings {
+		t.Errorf("got exit code %d (%v) but wanted %d", got, err, exitFindings)
+	}
+	for _, want := range []string{
+		"triggers.yaml:7:7: warning: the param gitrevision is declared by build-template but is not used by its resourcetemplates [template-params]",
+		"triggers.yaml:14:7: error: $(tt.params.account) is used but account is not declared by build-template [template-params]",
+		"triggers.yaml:23:11: error: build-run- does not supply the param revision which build-pipeline requires [run-params]",
+		"triggers.yaml:25:13: warning: build-run- supplies the param revison which is not declared by build-pipeline, did you mean revision? [run-params]",
+		"triggers.yaml:56:9: error: push does not bind the param gitrepositoryurl which the TriggerTemplate build-template requires [binding-params]",
+		"triggers.yaml:63:9: error: pull-request refers to the TriggerBinding pr-binding which does not exist [trigger-refs]",
+		"triggers.yaml:68:9: error: tag refers to the TriggerTemplate tag-template which does not exist [trigger-refs]",
+		"triggers.yaml:69:7: error: listener.triggers[3] refers to the Trigger release which does not exist [trigger-refs]",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("wanted %q in the output but got:\n%s", want, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "manual verified!") {
+		t.Errorf("did not expect diagnostics of the Trigger manual but got:\n%s", out.String())
+	}
+}
+
+// only the objects that match --selector must be validated, but their references must be looked up in all of them
+func TestValidateTriggersSelector(t *testing.T) {
+	dir := t.TempDir()
+	labelled := strings.Replace(yTrigger, "  name: manual\n", "  name: manual\n  labels:\n    team: a\n", 1)
+	writeFile(t, filepath.Join(dir, "triggers.yaml"), strings.Join([]string{yTriggerTemplate, yEventListener, labelled, yClusterTriggerBinding}, "---\n"))
+	set, err := loadManifests([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector, err := labels.Parse("team=a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := set.countTriggers(selector); got != 1 {
+		t.Errorf("got %d selected objects but wanted the Trigger manual only", got)
+	}
+
+	var out strings.Builder
+	if err := validateTriggers(&out, nil, set, selector); err != nil {
+		t.Errorf("did not expect an error but got %v:\n%s", err, out.String())
+	}
+	if got := strings.TrimSpace(out.String()); !strings.Contains(got, "manual verified!") || strings.Contains(got, "build-template") || strings.Contains(got, "listener") {
+		t.Errorf("wanted the Trigger manual to be verified on its own but got:\n%s", got)
+	}
+}
+
+// ClusterTriggerBindings that can not be listed must be reported without stopping the validation.
+// The objects are listed without --selector, since the selected triggers may refer to the others.
+func TestListTriggers(t *testing.T) {
+	defer func(selector string) { pipelineSelector = selector }(pipelineSelector)
+	pipelineSelector = "team=a"
+
+	listKinds := map[schema.GroupVersionResource]string{
+		triggersResource("triggertemplates"):       "TriggerTemplateList",
+		triggersResource("triggerbindings"):        "TriggerBindingList",
+		triggersResource("clustertriggerbindings"): "ClusterTriggerBindingList",
+		triggersResource("eventlisteners"):         "EventListenerList",
+		triggersResource("triggers"):               "TriggerList",
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		testObject(t, yTriggerTemplate, "team-a"), testObject(t, yTriggerBinding, "team-a"), testObject(t, yEventListener, "team-a"),
+		testObject(t, yTrigger, "team-a"), testObject(t, yTriggerTemplate, "team-b"))
+	client.PrependReactor("list", "clustertriggerbindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, forbidden(action.GetResource().Resource)
+	})
+
+	set, err := listTriggers(client, "team-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.triggerTemplates) != 1 || len(set.triggerBindings) != 1 || len(set.eventListeners) != 1 || len(set.triggers) != 1 {
+		t.Errorf("got %d TriggerTemplates, %d bindings, %d EventListeners and %d Triggers but wanted one of each",
+			len(set.triggerTemplates), len(set.triggerBindings), len(set.eventListeners), len(set.triggers))
+	}
+	if len(set.invalid) != 1 || set.invalid[0].kind != "ClusterTriggerBinding" {
+		t.Errorf("wanted the ClusterTriggerBindings to be unreadable but got %v", set.invalid)
+	}
+	assertion(t, set.ValidateTrigger("manual", "team-a", set.triggers[0].Spec.Bindings, set.triggers[0].Spec.Template), nil)
+}
@@ -7,49 +7,64 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"sort"
+	"io"
 
 	"github.com/spf13/cobra"
 	tknv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 type (
 	extendedPipeline    tknv1beta1.Pipeline
 	extendedTask        tknv1beta1.Task
 	extendedClusterTask tknv1beta1.ClusterTask
-	allTasks            interface {
+	extendedPipelineRun tknv1beta1.PipelineRun
+	// inlineTask is the anonymous task that a pipelineTask embeds as its taskSpec. It is named after the pipelineTask.
+	inlineTask struct {
+		pipelineTask string
+		spec         tknv1beta1.TaskSpec
+	}
+	allTasks interface {
 		getName() string
 		getParams() []tknv1beta1.ParamSpec
 		getWorkspaces() []tknv1beta1.WorkspaceDeclaration
+		getResults() []tknv1beta1.TaskResult
 	}
 )
 
 func (et extendedTask) getName() string                                  { return et.GetName() }
 func (et extendedTask) getParams() []tknv1beta1.ParamSpec                { return et.Spec.Params }
 func (et extendedTask) getWorkspaces() []tknv1beta1.WorkspaceDeclaration { return et.Spec.Workspaces }
+func (et extendedTask) getResults() []tknv1beta1.TaskResult              { return et.Spec.Results }
 func (ect extendedClusterTask) getName() string                          { return ect.GetName() }
 func (ect extendedClusterTask) getParams() []tknv1beta1.ParamSpec        { return ect.Spec.Params }
 func (ect extendedClusterTask) getWorkspaces() []tknv1beta1.WorkspaceDeclaration {
 	return ect.Spec.Workspaces
 }
+func (ect extendedClusterTask) getResults() []tknv1beta1.TaskResult    { return ect.Spec.Results }
+func (it inlineTask) getName() string                                  { return it.pipelineTask }
+func (it inlineTask) getParams() []tknv1beta1.ParamSpec                { return it.spec.Params }
+func (it inlineTask) getWorkspaces() []tknv1beta1.WorkspaceDeclaration { return it.spec.Workspaces }
+func (it inlineTask) getResults() []tknv1beta1.TaskResult              { return it.spec.Results }
 
 var (
-	eClusterTasks []extendedClusterTask
-	pipelineFile  string
-	normal        = "\033[0m"
-	bold          = "\033[1m"
-	red           = "\033[31m"
-	green         = "\033[32m"
-	yellow        = "\033[33m"
+	pipelineFiles []string
+	tasksFiles    []string
+	tasksDirs     []string
+	outputFormat  string
+	failOn        string
+	namespace     string
+	allNamespaces bool
+	// --selector and --pipeline
+	pipelineSelector string
+	pipelineName     string
+	normal           = "\033[0m"
+	bold             = "\033[1m"
+	red              = "\033[31m"
+	green            = "\033[32m"
+	yellow           = "\033[33m"
 )
 
 var validateCmd = &cobra.Command{
@@ -58,49 +73,78 @@ var validateCmd = &cobra.Command{
 	Long: `Validates the pipelines that are installed on the
 	cluster by ensuring the following:
 	- tasksRefs that are used in pipeline, must exist in the cluster
-	- task params that don't have default value must be present in pipelines
-	- task workspaces that are not optional must be present in pipeline`,
-	Run: func(cmd *cobra.Command, args []string) {
-		client := GetDynamicClient(kubeconfig)
-		clusterTasks, err := client.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "clustertasks"}).List(context.TODO(), v1.ListOptions{})
-		if err != nil {
-			panic(err)
-		}
-		for _, t := range clusterTasks.Items {
-			var eCT extendedClusterTask
-			err = runtime.DefaultUnstructuredConverter.FromUnstructured(t.Object, &eCT)
+	- tasksRefs must use the kind (Task or ClusterTask) of the object that exists
+	- task params that don't have default value must be supplied by the pipelineTask
+	- params that pipelineTasks reference must be declared in the pipeline
+	- params that pipelineTasks pass must be declared by the task
+	- param values, references and defaults must match the param types
+	- task results that are referenced must be declared by the tasks
+	- runAfter must refer to pipelineTasks in spec.tasks
+	- pipelineTasks must have unique names and must not depend on each other in a cycle
+	- task workspaces that are not optional must be present in pipeline
+	- params and workspaces of the pipeline must be used by its pipelineTasks
+	- pipelines and tasks must be readable, the objects that can not be read or
+	  converted are reported and do not stop the validation of the other pipelines
+
+	The pipelines in the namespace of the current kubeconfig context are validated, use
+	--namespace, --all-namespaces, --selector and --pipeline to pick other pipelines.
+	Earlier versions validated the pipelines of all the namespaces by default, use
+	--all-namespaces for that.
+	Pipelines, tasks and pipelineRuns can be tekton.dev/v1 or tekton.dev/v1beta1, the
+	cluster is asked which versions it serves and the newest one is used.
+	The cluster is found the same way as kubectl does, through --kubeconfig, $KUBECONFIG
+	or the in-cluster config, and --context, --cluster and --user override the context.
+
+	taskRefs that use the git, cluster, hub or bundles resolvers are fetched from
+	--git-checkout, the cluster, --hub-catalog and --bundle and validated as well.
+
+	When --tasks-file or --tasks-dir is provided, the tasks and clusterTasks
+	are read from those manifests instead of the cluster and no kubeconfig is needed.
+
+	Use --output json, --output sarif or --output junit to get machine readable results.
+
+	mario exits with 1 when the validations report diagnostics at or above --fail-on
+	(error by default), 2 when the flags, the input files or the kubeconfig are not
+	valid, 3 when the cluster can not be reached and 4 for any other error, e.g. when
+	the results can not be printed.`,
+	RunE: validateRunE(nil, func(cmd *cobra.Command, args []string) error {
+		var set manifestSet
+		if len(pipelineFiles) > 0 {
+			var err error
+			set, err = loadManifests(pipelineFiles)
 			if err != nil {
-				panic(err.Error())
+				return usageError(err)
 			}
-			eClusterTasks = append(eClusterTasks, eCT)
-		}
-
-		if pipelineFile == "" {
-			pipelines, err := client.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}).List(context.TODO(), v1.ListOptions{})
-			if err != nil {
-				panic(err.Error())
+			if len(set.pipelines) == 0 {
+				return usageError(fmt.Errorf("no pipelines found in %v", pipelineFiles))
 			}
-			for _, p := range pipelines.Items {
-				var eP extendedPipeline
-				err = runtime.DefaultUnstructuredConverter.FromUnstructured(p.Object, &eP)
-				if err != nil {
-					panic(err.Error())
-				}
-				eTasks := tasksInNamespace(eP.GetNamespace(), client)
-				errMap := runValidations(&eP, eTasks, eClusterTasks)
-				printErrors(errMap, &eP)
+			if set, err = selectPipelines(set); err != nil {
+				return usageError(err)
 			}
-		} else {
-			file, err := ioutil.ReadFile(pipelineFile)
-			if err != nil {
-				log.Fatal(err)
+			if len(set.pipelines) == 0 {
+				return usageError(fmt.Errorf("no pipelines in %v match --pipeline and --selector", pipelineFiles))
 			}
-			eP := setupPipeline([]byte(file))
-			eTasks := tasksInNamespace(eP.GetNamespace(), client)
-			errMap := runValidations(&eP, eTasks, eClusterTasks)
-			printErrors(errMap, &eP)
 		}
-	},
+
+		if len(tasksFiles) > 0 || len(tasksDirs) > 0 {
+			return validateOffline(cmd.OutOrStdout(), set)
+		}
+
+		client, ns, err := connect()
+		if err != nil {
+			return err
+		}
+		// pipelines in the files that do not have a namespace are validated against the tasks of the namespace
+		for i := range set.pipelines {
+			if set.pipelines[i].GetNamespace() == "" {
+				set.pipelines[i].SetNamespace(ns)
+			}
+		}
+		if allNamespaces {
+			ns = ""
+		}
+		return validateCluster(cmd.OutOrStdout(), client, set, ns)
+	}),
 }
 
 func init() {
@@ -112,24 +156,157 @@ func init() {
 	// and all subcommands, e.g.:
 	// validateCmd.PersistentFlags().String("foo", "", "A help for foo")
 
+	// flags that the subcommands, e.g. validate pipelinerun, share
+	validateCmd.PersistentFlags().StringArrayVarP(&pipelineFiles, "pipeline-file", "f", nil, "If provided, mario will validate the objects in these files only. Can be repeated and accepts multi-document files, directories and glob patterns")
+	validateCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", fmt.Sprintf("Output format, one of %v", outputFormats))
+	validateCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "Namespace of the objects to validate, defaults to the namespace of the current kubeconfig context")
+	validateCmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Validate the objects in all the namespaces")
+	validateCmd.PersistentFlags().StringVarP(&pipelineSelector, "selector", "l", "", "Label selector of the objects to validate, e.g. team=a,tier!=test")
+	validateCmd.PersistentFlags().StringVar(&failOn, "fail-on", failOnError, fmt.Sprintf("Lowest severity of the diagnostics that makes mario exit with %d, one of %v", exitFindings, failOnValues))
+
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
-	validateCmd.Flags().StringVarP(&pipelineFile, "pipeline-file", "f", "", "If provided, mario will validate this pipeline only")
+	validateCmd.Flags().StringSliceVar(&tasksFiles, "tasks-file", nil, "Files containing the Task and ClusterTask manifests to validate against instead of the cluster")
+	validateCmd.Flags().StringSliceVar(&tasksDirs, "tasks-dir", nil, "Directories (searched recursively) containing the Task and ClusterTask manifests to validate against instead of the cluster")
+	validateCmd.Flags().StringVar(&pipelineName, "pipeline", "", "Name of the pipeline to validate")
+	validateCmd.Flags().StringToStringVar(&gitCheckouts, "git-checkout", nil, "Local checkout of a repository that the git resolver uses, as URL=DIR or ORG/REPO=DIR, can be repeated")
+	validateCmd.Flags().StringVar(&hubCatalog, "hub-catalog", "", "Local copy of the tekton catalog that the hub resolver uses")
+	validateCmd.Flags().StringToStringVar(&bundleDirs, "bundle", nil, "Directory with the manifests of a tekton bundle, as IMAGE=DIR, can be repeated")
 }
 
-// Ensures that all the tasks and clusterTasks which are referred in a pipeline, exist in the cluster.
-func (p *extendedPipeline) ValidateTaskRefs(cTasks []extendedTask, cClusterTasks []extendedClusterTask) error {
-	var pTasksNames, pClusterTasksNames, cTasksNames, cClusterTasksNames []string
+// Ensures that the values of the flags that the validate commands share are valid
+func checkFlags() error {
+	if !sliceIncludeString(outputFormats, outputFormat) {
+		return usageError(fmt.Errorf("unknown output format %s, must be one of %v", outputFormat, outputFormats))
+	}
+	if !sliceIncludeString(failOnValues, failOn) {
+		return usageError(fmt.Errorf("unknown --fail-on value %s, must be one of %v", failOn, failOnValues))
+	}
+	if namespace != "" && allNamespaces {
+		return usageError(errors.New("--namespace and --all-namespaces can not be used together"))
+	}
+	return nil
+}
 
-	for _, t := range allPipelineTasks(p) {
-		if t.TaskRef != nil {
-			if t.TaskRef.Kind == "ClusterTask" {
-				pClusterTasksNames = append(pClusterTasksNames, t.TaskRef.Name)
-			} else {
-				pTasksNames = append(pTasksNames, t.TaskRef.Name)
+// Returns the RunE of a validate command. The flags are checked by checkFlags and then by check, which can be
+// nil, and their errors print the usage of the command. The errors of run are not about the usage of the
+// command, e.g. an unreachable cluster, so the usage is not printed for them and the ones without a code,
+// e.g. the results can not be printed, exit with exitRuntime.
+func validateRunE(check, run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := checkFlags(); err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(cmd, args); err != nil {
+				return err
 			}
 		}
+		cmd.SilenceUsage = true
+		return runtimeError(run(cmd, args))
 	}
+}
+
+// Validates the pipelines against the tasks and clusterTasks that are read from the
+// local manifests, including the ones provided alongside the pipelines. It does not talk to the cluster.
+func validateOffline(w io.Writer, set manifestSet) error {
+	if len(set.pipelines) == 0 {
+		return usageError(errors.New("--pipeline-file must be provided when validating against local tasks"))
+	}
+	eTasks, eClusterTasks, invalid, err := loadTasks(append(tasksFiles, tasksDirs...))
+	if err != nil {
+		return usageError(err)
+	}
+	eTasks = mergeTasks(set.tasks, eTasks)
+	eClusterTasks = mergeClusterTasks(set.clusterTasks, eClusterTasks)
+	r := newResolvers(nil, nil, eTasks)
+	var results []pipelineResult
+	for i := range set.pipelines {
+		eP := &set.pipelines[i]
+		results = append(results, newPipelineResult(eP, set.sources[i], eTasks, eClusterTasks, r))
+	}
+	results = append(results, invalidResults(append(set.invalid, invalid...))...)
+	if err := printResults(w, results); err != nil {
+		return fmt.Errorf("printing the results: %w", err)
+	}
+	return resultsError(results)
+}
+
+// Validates the pipelines in the files, or the pipelines in the namespace (all namespaces if ns is empty) if no
+// file is provided, against the tasks and clusterTasks in the cluster. Objects that can not be converted and
+// pipelines whose tasks can not be listed are reported as diagnostics and do not stop the validation of the other
+// pipelines. When the clusterTasks can not be listed, e.g. without cluster-wide permissions, it is reported and
+// the pipelines are validated without them.
+func validateCluster(w io.Writer, client dynamic.Interface, set manifestSet, ns string) error {
+	var results []pipelineResult
+	var eClusterTasks []extendedClusterTask
+	var invalid []invalidObject
+	clusterTasks, err := listAll(context.TODO(), client.Resource(tektonResource("clustertasks")), v1.ListOptions{})
+	switch {
+	case apierrors.IsForbidden(err):
+		invalid = append(invalid, invalidObject{kind: "ClusterTask", name: "clustertasks", err: fmt.Errorf("listing clustertasks: %w", err)})
+	case err != nil:
+		return clusterError(fmt.Errorf("listing clustertasks: %w", err))
+	default:
+		eClusterTasks, invalid = convertClusterTasks(clusterTasks)
+	}
+
+	ePipelines, sources := set.pipelines, set.sources
+	if len(pipelineFiles) == 0 {
+		pipelines, err := listPipelines(client, ns)
+		if err != nil {
+			return err
+		}
+		var invalidPipelines []invalidObject
+		ePipelines, invalidPipelines = convertPipelines(pipelines)
+		sources = make([]source, len(ePipelines))
+		invalid = append(invalid, invalidPipelines...)
+	}
+
+	// tasks that are provided alongside the pipelines take precedence over the ones in the cluster
+	eClusterTasks = mergeClusterTasks(set.clusterTasks, eClusterTasks)
+	var namespaces []string
+	for i := range ePipelines {
+		namespaces = append(namespaces, ePipelines[i].GetNamespace())
+	}
+	catalog := buildTaskCatalog(context.TODO(), client, namespaces)
+	invalid = append(invalid, catalog.invalid()...)
+	r := newResolvers(client, catalog, set.tasks)
+	for i := range ePipelines {
+		eP := &ePipelines[i]
+		cTasks, err := catalog.tasks(eP.GetNamespace())
+		if err != nil {
+			results = append(results, unreadableTasksResult(eP, sources[i], err))
+			continue
+		}
+		results = append(results, newPipelineResult(eP, sources[i], mergeTasks(set.tasks, cTasks), eClusterTasks, r))
+	}
+	results = append(results, invalidResults(append(set.invalid, invalid...))...)
+	if err := printResults(w, results); err != nil {
+		return fmt.Errorf("printing the results: %w", err)
+	}
+	return resultsError(results)
+}
+
+// Returns the result of a pipeline which is not validated because the tasks in its namespace can not be listed
+func unreadableTasksResult(eP *extendedPipeline, src source, err error) pipelineResult {
+	d := diagnostic{
+		rule:     ruleUnreadable,
+		severity: severityError,
+		location: location{field: "metadata.namespace"},
+		subject:  eP.GetNamespace(),
+		message:  fmt.Sprintf("%s is not validated: %s", eP.GetName(), err),
+	}
+	diags := []diagnostic{d}
+	src.locate(diags)
+	return pipelineResult{subject: newSubject(eP.Kind, eP.ObjectMeta, src), diagnostics: diags}
+}
+
+// Ensures that all the tasks and clusterTasks which are referred in a pipeline exist, in the cluster or in the
+// local task manifests.
+func (p *extendedPipeline) ValidateTaskRefs(cTasks []extendedTask, cClusterTasks []extendedClusterTask) []diagnostic {
+	var cTasksNames, cClusterTasksNames []string
+	var diags []diagnostic
 
 	for _, t := range cTasks {
 		cTasksNames = append(cTasksNames, t.getName())
@@ -138,50 +315,172 @@ func (p *extendedPipeline) ValidateTaskRefs(cTasks []extendedTask, cClusterTasks
 		cClusterTasksNames = append(cClusterTasksNames, t.getName())
 	}
 
-	missingTasks := sliceOutliers(cTasksNames, pTasksNames)
-	missingTasks = append(missingTasks, sliceOutliers(cClusterTasksNames, pClusterTasksNames)...)
-	if len(missingTasks) > 0 {
-		sort.Strings(missingTasks)
-		return errors.New(fmt.Sprintf("The following tasks/clusterTasks are used in %s pipeline but do not exist in the cluster: %v", p.GetName(), missingTasks))
+	for _, t := range allPipelineTasks(p) {
+		// remote taskRefs are checked while they are resolved
+		if t.TaskRef == nil || isRemoteRef(t.TaskRef) {
+			continue
+		}
+		// a name which exists as the other kind is reported by ValidateTaskKinds
+		if sliceIncludeString(cTasksNames, t.TaskRef.Name) != sliceIncludeString(cClusterTasksNames, t.TaskRef.Name) {
+			continue
+		}
+		d := diagnostic{rule: ruleTaskRef, severity: severityError, location: location{pipelineTask: t.Name}, subject: t.TaskRef.Name}
+		if t.TaskRef.Kind == "ClusterTask" && !sliceIncludeString(cClusterTasksNames, t.TaskRef.Name) {
+			d.message = fmt.Sprintf("%s refers to the clusterTask %s which is not found", t.Name, t.TaskRef.Name)
+			diags = append(diags, d)
+		}
+		if t.TaskRef.Kind != "ClusterTask" && !sliceIncludeString(cTasksNames, t.TaskRef.Name) {
+			d.message = fmt.Sprintf("%s refers to the task %s which is not found", t.Name, t.TaskRef.Name)
+			diags = append(diags, d)
+		}
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that the kind of every taskRef matches the kind of the object which exists with that name.
+// For example if a pipeline refers to task-b as a ClusterTask but only a Task named task-b exists in the
+// namespace, it reports the mismatch and suggests the correct taskRef.kind.
+func (p *extendedPipeline) ValidateTaskKinds(cTasks []extendedTask, cClusterTasks []extendedClusterTask) []diagnostic {
+	var diags []diagnostic
+
+	for _, t := range allPipelineTasks(p) {
+		if t.TaskRef == nil || t.TaskRef.Name == "" || isRemoteRef(t.TaskRef) {
+			continue
+		}
+		d := diagnostic{rule: ruleTaskKind, severity: severityError, location: location{pipelineTask: t.Name}, subject: t.TaskRef.Name}
+		if t.TaskRef.Kind == "ClusterTask" {
+			if findClusterTask(cClusterTasks, t.TaskRef.Name) == nil && findTask(cTasks, t.TaskRef.Name) != nil {
+				d.message = fmt.Sprintf("%s refers to %s as a ClusterTask but it exists as a Task", t.Name, t.TaskRef.Name)
+				d.suggestion = "set taskRef.kind to Task"
+				diags = append(diags, d)
+			}
+		} else {
+			if findTask(cTasks, t.TaskRef.Name) == nil && findClusterTask(cClusterTasks, t.TaskRef.Name) != nil {
+				d.message = fmt.Sprintf("%s refers to %s as a Task but it exists as a ClusterTask", t.Name, t.TaskRef.Name)
+				d.suggestion = "set taskRef.kind to ClusterTask"
+				diags = append(diags, d)
+			}
+		}
 	}
-	return nil
+	return sortDiagnostics(diags)
 }
 
-// Ensures that all the non-default params that pipelineTasks need are present in the spec.params of the pipeline
-func (p *extendedPipeline) ValidateParams(cTasks []extendedTask, cClusterTasks []extendedClusterTask) error {
-	var pParamNames []string
-	pTasksWithMissingParams := make(map[string][]string)
+// Ensures that all the non-default params that a task needs are supplied by the pipelineTask which refers to it.
+// Params that are supplied to one pipelineTask do not satisfy the params of another pipelineTask.
+func (p *extendedPipeline) ValidateParams(cTasks []extendedTask, cClusterTasks []extendedClusterTask, rTasks resolvedTasks) []diagnostic {
+	var diags []diagnostic
 
 	for _, pt := range allPipelineTasks(p) {
-		for _, p := range pt.Params {
-			pParamNames = append(pParamNames, p.Name)
+		var pTaskParamNames, requiredParamsList []string
+		for _, param := range pt.Params {
+			pTaskParamNames = append(pTaskParamNames, param.Name)
+		}
+		// matrix params are fanned out and supplied to the task as well
+		if pt.Matrix != nil {
+			for _, param := range pt.Matrix.Params {
+				pTaskParamNames = append(pTaskParamNames, param.Name)
+			}
+		}
+
+		if ct := resolveTask(pt, cTasks, cClusterTasks, rTasks); ct != nil {
+			requiredParamsList = requiredParams(ct)
+		}
+		for _, param := range uniqueStrings(sliceOutliers(pTaskParamNames, requiredParamsList)) {
+			diags = append(diags, diagnostic{
+				rule:     ruleParams,
+				severity: severityError,
+				location: location{pipelineTask: pt.Name},
+				subject:  param,
+				message:  fmt.Sprintf("%s is missing the param %s", pt.Name, param),
+			})
 		}
 	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that every $(params.x) which is referenced in the params, matrix or when expressions of a
+// pipelineTask is declared in the spec.params of the pipeline.
+func (p *extendedPipeline) ValidateParamReferences() []diagnostic {
+	var pParamNames []string
+	var diags []diagnostic
+
+	for _, param := range p.Spec.Params {
+		pParamNames = append(pParamNames, param.Name)
+	}
 
 	for _, pt := range allPipelineTasks(p) {
-		var requiredParamsList []string
-		for _, ct := range allclusterTasks(cTasks, cClusterTasks) {
-			requiredParamsList = append(requiredParamsList, requiredParams(pt, ct)...)
+		for _, v := range stringValues([]interface{}{pt.Params, pt.Matrix, pt.WhenExpressions}) {
+			for _, ref := range paramReferences(v) {
+				if sliceIncludeString(pParamNames, ref.name) {
+					continue
+				}
+				d := diagnostic{
+					rule:     ruleParamRefs,
+					severity: severityError,
+					location: location{pipelineTask: pt.Name},
+					subject:  ref.name,
+					message:  fmt.Sprintf("%s references $(params.%s) which is not declared in spec.params", pt.Name, ref.name),
+				}
+				if suggestion := closestString(ref.name, pParamNames); suggestion != "" {
+					d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+				}
+				if !includesDiagnostic(diags, d) {
+					diags = append(diags, d)
+				}
+			}
 		}
-		missingParams := sliceOutliers(pParamNames, requiredParamsList)
-		if len(missingParams) < 1 {
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that every param which a pipelineTask passes is declared by the task or clusterTask that it refers to.
+// When an undeclared param looks like a typo of a declared one, the declared param is suggested.
+func (p *extendedPipeline) ValidateUndeclaredParams(cTasks []extendedTask, cClusterTasks []extendedClusterTask, rTasks resolvedTasks) []diagnostic {
+	var diags []diagnostic
+
+	for _, pt := range allPipelineTasks(p) {
+		ct := resolveTask(pt, cTasks, cClusterTasks, rTasks)
+		if ct == nil {
 			continue
 		}
-		pTasksWithMissingParams[pt.Name] = missingParams
-	}
+		var cTaskParamNames, pTaskParamNames []string
+		for _, cp := range ct.getParams() {
+			cTaskParamNames = append(cTaskParamNames, cp.Name)
+		}
+		for _, param := range pt.Params {
+			pTaskParamNames = append(pTaskParamNames, param.Name)
+		}
+		if pt.Matrix != nil {
+			for _, param := range pt.Matrix.Params {
+				pTaskParamNames = append(pTaskParamNames, param.Name)
+			}
+		}
 
-	if len(pTasksWithMissingParams) != 0 {
-		return errors.New(fmt.Sprintf("%s is missing the following params:\n%v", p.GetName(), pTasksWithMissingParams))
+		// only the params that are not supplied yet are worth suggesting
+		candidates := sliceOutliers(pTaskParamNames, cTaskParamNames)
+		for _, name := range sliceOutliers(cTaskParamNames, pTaskParamNames) {
+			d := diagnostic{
+				rule:     ruleUndeclaredParams,
+				severity: severityError,
+				location: location{pipelineTask: pt.Name},
+				subject:  name,
+				message:  fmt.Sprintf("%s passes the param %s which is not declared by %s", pt.Name, name, ct.getName()),
+			}
+			if suggestion := closestString(name, candidates); suggestion != "" {
+				d.suggestion = fmt.Sprintf("did you mean %s?", suggestion)
+			}
+			diags = append(diags, d)
+		}
 	}
-	return nil
+	return sortDiagnostics(diags)
 }
 
 // Ensures that all the non-optional workspaces that pipelineTasks need are present in the spec.workspaces of the pipeline.
 // It does consider the correct binding. For example if taskA needs ws-a however ws-a is bound to ws-1 in the pipelineTask,
 // it expects the pipeline to have ws-a in it's spec.workspaces
-func (p *extendedPipeline) ValidateWorkspaces(cTasks []extendedTask, cClusterTasks []extendedClusterTask) error {
+func (p *extendedPipeline) ValidateWorkspaces(cTasks []extendedTask, cClusterTasks []extendedClusterTask, rTasks resolvedTasks) []diagnostic {
 	var pWorkspaceNames []string
-	pTasksWithMissingWorkspaces := make(map[string][]string)
+	var diags []diagnostic
 
 	for _, w := range p.Spec.Workspaces {
 		pWorkspaceNames = append(pWorkspaceNames, w.Name)
@@ -189,78 +488,227 @@ func (p *extendedPipeline) ValidateWorkspaces(cTasks []extendedTask, cClusterTas
 
 	for _, pt := range allPipelineTasks(p) {
 		var requiredWorkspaceList []string
-		for _, ct := range allclusterTasks(cTasks, cClusterTasks) {
-			requiredWorkspaceList = append(requiredWorkspaceList, requiredWorkspaces(pt, ct)...)
+		if ct := resolveTask(pt, cTasks, cClusterTasks, rTasks); ct != nil {
+			requiredWorkspaceList = requiredWorkspaces(pt, ct)
 		}
-		missingWorkspaces := sliceOutliers(pWorkspaceNames, requiredWorkspaceList)
-		if len(missingWorkspaces) < 1 {
-			continue
+		for _, w := range uniqueStrings(sliceOutliers(pWorkspaceNames, requiredWorkspaceList)) {
+			diags = append(diags, diagnostic{
+				rule:     ruleWorkspaces,
+				severity: severityError,
+				location: location{pipelineTask: pt.Name},
+				subject:  w,
+				message:  fmt.Sprintf("%s needs the workspace %s which is not declared in spec.workspaces", pt.Name, w),
+			})
 		}
-		pTasksWithMissingWorkspaces[pt.Name] = missingWorkspaces
 	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that every param in spec.params of the pipeline is used by at least one pipelineTask. Params are
+// considered used when they are referenced as $(params.x) anywhere in spec.tasks or spec.finally, including
+// inline taskSpecs, when expressions and matrix, or in spec.results of the pipeline.
+func (p *extendedPipeline) ValidateUnusedParams() []diagnostic {
+	var usedParamNames []string
+	var diags []diagnostic
 
-	if len(pTasksWithMissingWorkspaces) > 0 {
-		return errors.New(fmt.Sprintf("%s is missing the following workspaces:\n%v", p.GetName(), pTasksWithMissingWorkspaces))
+	for _, v := range append(stringValues(allPipelineTasks(p)), stringValues(p.Spec.Results)...) {
+		for _, ref := range paramReferences(v) {
+			usedParamNames = append(usedParamNames, ref.name)
+		}
 	}
-	return nil
+
+	for _, param := range p.Spec.Params {
+		if !sliceIncludeString(usedParamNames, param.Name) {
+			diags = append(diags, diagnostic{
+				rule:     ruleUnusedParams,
+				severity: severityWarning,
+				location: location{field: "spec.params"},
+				subject:  param.Name,
+				message:  fmt.Sprintf("the param %s is not used by any pipelineTask", param.Name),
+			})
+		}
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that every workspace in spec.workspaces of the pipeline is used by at least one pipelineTask. Workspaces
+// are considered used when they are bound to a pipelineTask workspace or when they are referenced as
+// $(workspaces.x.path) and similar variables anywhere in spec.tasks or spec.finally.
+func (p *extendedPipeline) ValidateUnusedWorkspaces() []diagnostic {
+	var usedWorkspaceNames []string
+	var diags []diagnostic
+
+	for _, pt := range allPipelineTasks(p) {
+		for _, w := range pt.Workspaces {
+			if w.Workspace == "" {
+				usedWorkspaceNames = append(usedWorkspaceNames, w.Name)
+			} else {
+				usedWorkspaceNames = append(usedWorkspaceNames, w.Workspace)
+			}
+		}
+	}
+	for _, v := range stringValues(allPipelineTasks(p)) {
+		usedWorkspaceNames = append(usedWorkspaceNames, workspaceReferences(v)...)
+	}
+
+	for _, w := range p.Spec.Workspaces {
+		if !sliceIncludeString(usedWorkspaceNames, w.Name) {
+			diags = append(diags, diagnostic{
+				rule:     ruleUnusedWorkspaces,
+				severity: severityWarning,
+				location: location{field: "spec.workspaces"},
+				subject:  w.Name,
+				message:  fmt.Sprintf("the workspace %s is not used by any pipelineTask", w.Name),
+			})
+		}
+	}
+	return sortDiagnostics(diags)
+}
+
+// Ensures that every $(tasks.x.results.y) reference points to a pipelineTask that exists and to a result
+// that its task declares. Results of finally tasks can only be used in spec.results of the pipeline.
+func (p *extendedPipeline) ValidateResultRefs(cTasks []extendedTask, cClusterTasks []extendedClusterTask, rTasks resolvedTasks) []diagnostic {
+	var diags []diagnostic
+
+	pTasks := make(map[string]tknv1beta1.PipelineTask)
+	for _, pt := range allPipelineTasks(p) {
+		pTasks[pt.Name] = pt
+	}
+
+	check := func(loc location, values []string, allowFinally bool) {
+		for _, v := range values {
+			for _, ref := range resultReferences(v) {
+				d := diagnostic{rule: ruleResultRefs, severity: severityError, location: loc, subject: ref.pipelineTask}
+				refTask, ok := pTasks[ref.pipelineTask]
+				switch {
+				case !ok:
+					d.message = fmt.Sprintf("$(tasks.%s.results.%s) refers to %s which is not a pipelineTask", ref.pipelineTask, ref.result, ref.pipelineTask)
+				case !allowFinally && isFinallyTask(p, ref.pipelineTask):
+					d.message = fmt.Sprintf("$(tasks.%s.results.%s) refers to %s which is a finally task", ref.pipelineTask, ref.result, ref.pipelineTask)
+				default:
+					ct := resolveTask(refTask, cTasks, cClusterTasks, rTasks)
+					if ct != nil && !taskHasResult(ct, ref.result) {
+						d.subject = ref.result
+						d.message = fmt.Sprintf("$(tasks.%s.results.%s) refers to %s result which is not declared by %s", ref.pipelineTask, ref.result, ref.result, ct.getName())
+					}
+				}
+				if d.message != "" && !includesDiagnostic(diags, d) {
+					diags = append(diags, d)
+				}
+			}
+		}
+	}
+
+	for _, pt := range allPipelineTasks(p) {
+		check(location{pipelineTask: pt.Name}, stringValues([]interface{}{pt.Params, pt.Matrix, pt.WhenExpressions}), false)
+	}
+	check(location{field: "spec.results"}, stringValues(p.Spec.Results), true)
+	return sortDiagnostics(diags)
+}
+
+// Ensures that every runAfter entry refers to a pipelineTask in spec.tasks. Finally tasks can neither
+// use runAfter nor be used in runAfter since they always run after all the spec.tasks.
+func (p *extendedPipeline) ValidateRunAfter() []diagnostic {
+	var diags []diagnostic
+
+	for _, pt := range p.Spec.Tasks {
+		for _, name := range pt.RunAfter {
+			d := diagnostic{rule: ruleRunAfter, severity: severityError, location: location{pipelineTask: pt.Name}, subject: name}
+			switch {
+			case isFinallyTask(p, name):
+				d.message = fmt.Sprintf("runAfter refers to %s which is a finally task", name)
+			case !isPipelineTask(p, name):
+				d.message = fmt.Sprintf("runAfter refers to %s which is not a pipelineTask", name)
+			default:
+				continue
+			}
+			diags = append(diags, d)
+		}
+	}
+	for _, pt := range p.Spec.Finally {
+		if len(pt.RunAfter) > 0 {
+			diags = append(diags, diagnostic{
+				rule:     ruleRunAfter,
+				severity: severityError,
+				location: location{pipelineTask: pt.Name},
+				subject:  pt.Name,
+				message:  "finally tasks cannot use runAfter",
+			})
+		}
+	}
+	return sortDiagnostics(diags)
 }
 
-// Returns all the parameters that are required by a given pipelineTask.
+// Returns all the parameters that are required by the task that a pipelineTask refers to.
 // It does not include parameters that have a default value. The reason is that
 // spec.param of a pipeline doesn't need to have the task params that have default value.
-func requiredParams(pTask tknv1beta1.PipelineTask, cTask allTasks) []string {
+func requiredParams(cTask allTasks) []string {
 	var paramsThatPipelineMustHave []string
-	if pTask.TaskRef != nil && cTask.getName() == pTask.TaskRef.Name {
-		for _, cp := range cTask.getParams() {
-			if cp.Default == nil {
-				paramsThatPipelineMustHave = append(paramsThatPipelineMustHave, cp.Name)
-			}
+	for _, cp := range cTask.getParams() {
+		if cp.Default == nil {
+			paramsThatPipelineMustHave = append(paramsThatPipelineMustHave, cp.Name)
 		}
 	}
 	return paramsThatPipelineMustHave
 }
 
-// Returns all the workspaces that are required by a given a pipelineTask.
+// Returns all the workspaces that are required by a given pipelineTask and the task that it refers to.
 // It does not include the task workspaces which are optional. If a pipelineTask
 // does not declare the workspace, then the workspace which is declared in the
 // spec.workspace of pipeline must have the same name as the task workspace.
 func requiredWorkspaces(pTask tknv1beta1.PipelineTask, cTask allTasks) (workspacesThatPipelineMustHave []string) {
-	if pTask.TaskRef != nil && cTask.getName() == pTask.TaskRef.Name {
-		var cTaskWorkspaceNames, pTaskWorkspaceNames []string
-		for _, ws := range cTask.getWorkspaces() {
-			if !ws.Optional {
-				cTaskWorkspaceNames = append(cTaskWorkspaceNames, ws.Name)
-			}
+	var cTaskWorkspaceNames, pTaskWorkspaceNames []string
+	for _, ws := range cTask.getWorkspaces() {
+		if !ws.Optional {
+			cTaskWorkspaceNames = append(cTaskWorkspaceNames, ws.Name)
 		}
+	}
 
-		for _, ws := range pTask.Workspaces {
-			pTaskWorkspaceNames = append(pTaskWorkspaceNames, ws.Name)
-		}
+	for _, ws := range pTask.Workspaces {
+		pTaskWorkspaceNames = append(pTaskWorkspaceNames, ws.Name)
+	}
 
-		// any workspace which is declared in task but not in pipelineTask must exist in spec.workspace of the pipeline
-		workspacesThatPipelineMustHave = append(workspacesThatPipelineMustHave, sliceOutliers(pTaskWorkspaceNames, cTaskWorkspaceNames)...)
+	// any workspace which is declared in task but not in pipelineTask must exist in spec.workspace of the pipeline
+	workspacesThatPipelineMustHave = append(workspacesThatPipelineMustHave, sliceOutliers(pTaskWorkspaceNames, cTaskWorkspaceNames)...)
 
-		// if pipelineTask is defining a workspace, check the binidng to make sure that pipeline actually has the correct ws name
-		for _, w := range pTask.Workspaces {
-			if w.Workspace == "" {
-				workspacesThatPipelineMustHave = append(workspacesThatPipelineMustHave, w.Name)
-			} else {
-				workspacesThatPipelineMustHave = append(workspacesThatPipelineMustHave, w.Workspace)
-			}
+	// if pipelineTask is defining a workspace, check the binidng to make sure that pipeline actually has the correct ws name
+	for _, w := range pTask.Workspaces {
+		if w.Workspace == "" {
+			workspacesThatPipelineMustHave = append(workspacesThatPipelineMustHave, w.Name)
+		} else {
+			workspacesThatPipelineMustHave = append(workspacesThatPipelineMustHave, w.Workspace)
 		}
 	}
 	return
 }
 
-// Returns a list of all tasks and clustertasks
-func allclusterTasks(tList []extendedTask, ctList []extendedClusterTask) (tAll []allTasks) {
-	for _, t := range ctList {
-		tAll = append(tAll, t)
+// Returns the task or clusterTask that a pipelineTask refers to, considering the kind of the taskRef.
+// For pipelineTasks with an embedded taskSpec it returns the inline task. It returns nil if the
+// pipelineTask does not have a taskRef or the task does not exist. Remote taskRefs are looked up in the
+// tasks that were resolved for them.
+func resolveTask(pTask tknv1beta1.PipelineTask, cTasks []extendedTask, cClusterTasks []extendedClusterTask, rTasks resolvedTasks) allTasks {
+	if pTask.TaskSpec != nil {
+		return inlineTask{pipelineTask: pTask.Name, spec: pTask.TaskSpec.TaskSpec}
 	}
-	for _, t := range tList {
-		tAll = append(tAll, t)
+	if pTask.TaskRef == nil {
+		return nil
 	}
-	return
+	if isRemoteRef(pTask.TaskRef) {
+		if t, ok := rTasks[remoteRefKey(pTask.TaskRef)]; ok {
+			return t
+		}
+		return nil
+	}
+	if pTask.TaskRef.Kind == "ClusterTask" {
+		if ct := findClusterTask(cClusterTasks, pTask.TaskRef.Name); ct != nil {
+			return *ct
+		}
+		return nil
+	}
+	if t := findTask(cTasks, pTask.TaskRef.Name); t != nil {
+		return *t
+	}
+	return nil
 }
 
 // Gets all the tasks in Spec and Finally
@@ -274,6 +722,36 @@ func allPipelineTasks(p *extendedPipeline) (pt []tknv1beta1.PipelineTask) {
 	return
 }
 
+// Returns true if there is a pipelineTask with the given name in spec.tasks
+func isPipelineTask(p *extendedPipeline, name string) bool {
+	for _, t := range p.Spec.Tasks {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if there is a pipelineTask with the given name in spec.finally
+func isFinallyTask(p *extendedPipeline, name string) bool {
+	for _, t := range p.Spec.Finally {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the task declares a result with the given name
+func taskHasResult(cTask allTasks, name string) bool {
+	for _, r := range cTask.getResults() {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Returns items in smallSlice which do not exist in the bigSlice
 func sliceOutliers(bigSlice, smallSlice []string) (outliers []string) {
 	for _, s := range smallSlice {
@@ -294,89 +772,147 @@ func sliceIncludeString(slice []string, s string) bool {
 	return false
 }
 
-// Give the kubeconfig path, it returns a dynamic client
-func GetDynamicClient(kubeconfig string) dynamic.Interface {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-	if err != nil {
-		panic(err)
-	}
-	client, err := dynamic.NewForConfig(config)
-	if err != nil {
-		panic(err)
+// Returns the candidate which is closest to s, as long as it is close enough to be a typo of s.
+// It returns an empty string if no candidate is close enough.
+func closestString(s string, candidates []string) (closest string) {
+	maxDistance := len(s) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	for _, c := range candidates {
+		if d := levenshtein(s, c); d <= maxDistance {
+			maxDistance = d
+			closest = c
+		}
 	}
-	return client
+	return
 }
 
-// Converts an array bites into a typed pipeline
-func setupPipeline(b []byte) (tPipeline extendedPipeline) {
-	jPipeline, err := yaml.ToJSON(b)
-	if err != nil {
-		panic(err.Error())
-	}
-	object, err := runtime.Decode(unstructured.UnstructuredJSONScheme, jPipeline)
-	if err != nil {
-		panic(err.Error())
-	}
-	uPipeline, ok := object.(*unstructured.Unstructured)
-	if !ok {
-		panic("unstructured.Unstructured expected")
-	}
-	err = runtime.DefaultUnstructuredConverter.FromUnstructured(uPipeline.Object, &tPipeline)
-	if err != nil {
-		panic(err.Error())
-	}
-	if tPipeline.Kind != "Pipeline" {
-		errorMessage := fmt.Sprintf("Expected object of kind Pipeline to be provided by the file but instead got a %s", tPipeline.Kind)
-		panic(errors.New(errorMessage).Error())
+// Returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
 	}
-	return tPipeline
+	return previous[len(b)]
 }
 
-// Gets all the tasks in a given namespace. It returns typed objects
-func tasksInNamespace(ns string, c dynamic.Interface) (eTasks []extendedTask) {
-	tasks, err := c.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}).Namespace(ns).List(context.TODO(), v1.ListOptions{})
-	if err != nil {
-		panic(err)
-	}
-	for _, t := range tasks.Items {
-		var eT extendedTask
-		err = runtime.DefaultUnstructuredConverter.FromUnstructured(t.Object, &eT)
-		if err != nil {
-			panic(err.Error())
-		}
-		eTasks = append(eTasks, eT)
-	}
-	return
+// A validation that runs against every pipeline. rule is the code of the diagnostics that the validation
+// reports, its name and default severity are in registeredRules.
+type validation struct {
+	rule string
+	run  func(eP *extendedPipeline, eTasks []extendedTask, eClusterTasks []extendedClusterTask, rTasks resolvedTasks) []diagnostic
 }
 
-// runs the pipeline validations
-func runValidations(eP *extendedPipeline, eTasks []extendedTask, eClusterTasks []extendedClusterTask) map[string]error {
-	errMap := make(map[string]error)
-	taskRefErr := eP.ValidateTaskRefs(eTasks, eClusterTasks)
-	if taskRefErr != nil {
-		errMap["taskRef validation"] = taskRefErr
-	}
-	paramsErr := eP.ValidateParams(eTasks, eClusterTasks)
-	if paramsErr != nil {
-		errMap["parameter validation"] = paramsErr
-	}
-	workspaceErr := eP.ValidateWorkspaces(eTasks, eClusterTasks)
-	if workspaceErr != nil {
-		errMap["workspace validation"] = workspaceErr
-	}
-	return errMap
+var pipelineValidations = []validation{
+	{
+		rule: ruleTaskRef,
+		run: func(eP *extendedPipeline, eTasks []extendedTask, eClusterTasks []extendedClusterTask, _ resolvedTasks) []diagnostic {
+			return eP.ValidateTaskRefs(eTasks, eClusterTasks)
+		},
+	},
+	{
+		rule: ruleTaskKind,
+		run: func(eP *extendedPipeline, eTasks []extendedTask, eClusterTasks []extendedClusterTask, _ resolvedTasks) []diagnostic {
+			return eP.ValidateTaskKinds(eTasks, eClusterTasks)
+		},
+	},
+	{
+		rule: ruleParams,
+		run:  (*extendedPipeline).ValidateParams,
+	},
+	{
+		rule: ruleWorkspaces,
+		run:  (*extendedPipeline).ValidateWorkspaces,
+	},
+	{
+		rule: ruleUndeclaredParams,
+		run:  (*extendedPipeline).ValidateUndeclaredParams,
+	},
+	{
+		rule: ruleParamTypes,
+		run:  (*extendedPipeline).ValidateParamTypes,
+	},
+	{
+		rule: ruleResultRefs,
+		run:  (*extendedPipeline).ValidateResultRefs,
+	},
+	{
+		rule: ruleRunAfter,
+		run: func(eP *extendedPipeline, _ []extendedTask, _ []extendedClusterTask, _ resolvedTasks) []diagnostic {
+			return eP.ValidateRunAfter()
+		},
+	},
+	{
+		rule: ruleGraph,
+		run: func(eP *extendedPipeline, _ []extendedTask, _ []extendedClusterTask, _ resolvedTasks) []diagnostic {
+			return eP.ValidateGraph()
+		},
+	},
+	{
+		rule: ruleParamRefs,
+		run: func(eP *extendedPipeline, _ []extendedTask, _ []extendedClusterTask, _ resolvedTasks) []diagnostic {
+			return eP.ValidateParamReferences()
+		},
+	},
+	{
+		rule: ruleUnusedParams,
+		run: func(eP *extendedPipeline, _ []extendedTask, _ []extendedClusterTask, _ resolvedTasks) []diagnostic {
+			return eP.ValidateUnusedParams()
+		},
+	},
+	{
+		rule: ruleUnusedWorkspaces,
+		run: func(eP *extendedPipeline, _ []extendedTask, _ []extendedClusterTask, _ resolvedTasks) []diagnostic {
+			return eP.ValidateUnusedWorkspaces()
+		},
+	},
 }
 
-// prints out the errors
-func printErrors(errMap map[string]error, eP *extendedPipeline) {
-	if len(errMap) == 0 {
-		fmt.Println(string(green), fmt.Sprintf("%s verified!", eP.GetName()), string(normal))
-	} else {
-		fmt.Println(string(yellow), fmt.Sprintf("%s has the following errors", eP.GetName()), string(normal))
-		for k, err := range errMap {
-			fmt.Println(string(red), k, "error", string(normal))
-			fmt.Println(err)
-		}
+// runs the pipeline validations and returns all the diagnostics that they report, sorted
+func runValidations(eP *extendedPipeline, eTasks []extendedTask, eClusterTasks []extendedClusterTask, rTasks resolvedTasks) (diags []diagnostic) {
+	for _, v := range pipelineValidations {
+		diags = append(diags, v.run(eP, eTasks, eClusterTasks, rTasks)...)
 	}
+	return sortDiagnostics(diags)
+}
+
+// Resolves the remote taskRefs of the pipeline, validates it and locates the diagnostics in the source that the
+// pipeline was read from
+func newPipelineResult(eP *extendedPipeline, src source, eTasks []extendedTask, eClusterTasks []extendedClusterTask, r resolvers) pipelineResult {
+	resolved, diags := r.resolvePipeline(eP)
+	diags = append(diags, runValidations(eP, eTasks, eClusterTasks, resolved)...)
+	src.locate(diags)
+	return pipelineResult{subject: newSubject(eP.Kind, eP.ObjectMeta, src), diagnostics: sortDiagnostics(diags)}
+}
 
+// Prints out the diagnostics of a pipeline. Every diagnostic is printed as file:line:column: severity: message
+// so that editors and terminals can jump to it.
+func printErrors(w io.Writer, r pipelineResult) {
+	if len(r.diagnostics) == 0 {
+		fmt.Fprintln(w, string(green), fmt.Sprintf("%s verified!", r.subject.name), string(normal))
+		return
+	}
+	fmt.Fprintln(w, string(yellow), fmt.Sprintf("%s has the following findings", r.subject.name), string(normal))
+	for _, d := range r.diagnostics {
+		fmt.Fprintf(w, "%s: %s: %s [%s]\n", r.where(d), d.severity, d.text(), d.rule)
+	}
 }
@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	"errors"
+	"strings"
 	"testing"
 
 	tknv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -12,21 +12,27 @@ type ValidateTaskRefsTestCases struct {
 	name          string
 	cTasks        []extendedTask
 	cClusterTasks []extendedClusterTask
-	want          error
+	want          []string
 }
 
 type ValidateParamsTestCases struct {
 	name          string
 	cTasks        []extendedTask
 	cClusterTasks []extendedClusterTask
-	want          error
+	want          []string
 }
 
 type ValidateWorkspacesTestCases struct {
 	name          string
 	cTasks        []extendedTask
 	cClusterTasks []extendedClusterTask
-	want          error
+	want          []string
+}
+
+type ValidatePipelineTestCases struct {
+	name     string
+	pipeline string
+	want     []string
 }
 
 var (
@@ -109,7 +115,7 @@ spec:
 // tasksRef must refer to tasks or clusterTasks that exist in cluster.
 // validate tasks in spec.tasks and spec.finally
 func TestValidateTaskRefs(t *testing.T) {
-	tPipeline := setupPipeline([]byte(yPipeline))
+	tPipeline := testPipeline(t, yPipeline)
 
 	validateTaskRefsTests := []ValidateTaskRefsTestCases{
 		{
@@ -127,7 +133,7 @@ func TestValidateTaskRefs(t *testing.T) {
 		},
 		{
 			name: "pipeline is using undeclared task 1",
-			want: errors.New("The following tasks/clusterTasks are used in test-pipeline pipeline but do not exist in the cluster: [task-a]"),
+			want: []string{"task-a: task-a refers to the task task-a which is not found"},
 			cTasks: []extendedTask{
 				{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
 				{ObjectMeta: metav1.ObjectMeta{Name: "task-z"}},
@@ -141,46 +147,99 @@ func TestValidateTaskRefs(t *testing.T) {
 			name: "pipeline is using undeclared cluster task 2",
 			cTasks: []extendedTask{
 				{ObjectMeta: metav1.ObjectMeta{Name: "task-a"}},
-				{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
 			},
 			cClusterTasks: []extendedClusterTask{
-				{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
 				{ObjectMeta: metav1.ObjectMeta{Name: "task-zzz"}},
 			},
-			want: errors.New("The following tasks/clusterTasks are used in test-pipeline pipeline but do not exist in the cluster: [task-b task-finally]"),
+			want: []string{
+				"task-b: task-b refers to the clusterTask task-b which is not found",
+				"task-finally: task-finally refers to the task task-finally which is not found",
+			},
 		},
 		{
-			name:   "pipeline is using undeclared cluster task 3",
+			name:   "names that exist as the other kind are left to the kind validation",
 			cTasks: []extendedTask{},
 			cClusterTasks: []extendedClusterTask{
 				{ObjectMeta: metav1.ObjectMeta{Name: "task-a"}},
 			},
-			want: errors.New("The following tasks/clusterTasks are used in test-pipeline pipeline but do not exist in the cluster: [task-a task-b task-finally]"),
+			want: []string{
+				"task-b: task-b refers to the clusterTask task-b which is not found",
+				"task-finally: task-finally refers to the task task-finally which is not found",
+			},
 		},
 	}
 
 	for _, tc := range validateTaskRefsTests {
 		t.Run(tc.name, func(t *testing.T) {
 			got := tPipeline.ValidateTaskRefs(tc.cTasks, tc.cClusterTasks)
-			if got == nil && tc.want != nil {
-				t.Errorf("\nwanted the following error: %s\nbut did not get any error", tc.want)
-			}
-			if got != nil {
-				if tc.want == nil {
-					t.Errorf("\ndid not expect error but got the following error: %s", got)
-				}
-				if tc.want.Error() != got.Error() {
-					t.Errorf("\ngot error: %v\nbut wanted: %v", got.Error(), tc.want)
-				}
-			}
+			assertion(t, got, tc.want)
+		})
+	}
+}
+
+// taskRefs must use the kind of the object that exists with that name
+func TestValidateTaskKinds(t *testing.T) {
+	tPipeline := testPipeline(t, yPipeline)
+
+	validateTaskKindsTests := []ValidateTaskRefsTestCases{
+		{
+			name: "pipeline uses the correct kinds",
+			want: nil,
+			cTasks: []extendedTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-a"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
+			},
+			cClusterTasks: []extendedClusterTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+			},
+		},
+		{
+			name:   "missing tasks are not kind mismatches",
+			want:   nil,
+			cTasks: []extendedTask{},
+		},
+		{
+			name: "both kinds exist",
+			want: nil,
+			cTasks: []extendedTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+			},
+			cClusterTasks: []extendedClusterTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+			},
+		},
+		{
+			name: "pipeline uses the wrong kinds",
+			want: []string{
+				"task-b: task-b refers to task-b as a ClusterTask but it exists as a Task, set taskRef.kind to Task",
+				"task-finally: task-finally refers to task-finally as a Task but it exists as a ClusterTask, set taskRef.kind to ClusterTask",
+			},
+			cTasks: []extendedTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-a"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+			},
+			cClusterTasks: []extendedClusterTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
+			},
+		},
+	}
+
+	for _, tc := range validateTaskKindsTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tPipeline.ValidateTaskKinds(tc.cTasks, tc.cClusterTasks)
+			assertion(t, got, tc.want)
 		})
 	}
+
+	// a kind mismatch is only reported by ValidateTaskKinds, not as a missing taskRef as well
+	mismatch := validateTaskKindsTests[len(validateTaskKindsTests)-1]
+	assertion(t, tPipeline.ValidateTaskRefs(mismatch.cTasks, mismatch.cClusterTasks), nil)
 }
 
 // spec.tasks[*].params and spec.finally[*].params must cover all the task
 // params that do not have a default value
 func TestValidateParams(t *testing.T) {
-	tPipeline := setupPipeline([]byte(yPipeline))
+	tPipeline := testPipeline(t, yPipeline)
 
 	validateParamsTests := []ValidateParamsTestCases{
 		{
@@ -218,7 +277,12 @@ func TestValidateParams(t *testing.T) {
 		},
 		{
 			name: "pipeline has a missing task param",
-			want: errors.New("test-pipeline is missing the following params:\nmap[task-a:[param-missed-1] task-b:[param-missed-3] task-finally:[param-missed-2 param-missed-3]]"),
+			want: []string{
+				"task-a: task-a is missing the param param-missed-1",
+				"task-b: task-b is missing the param param-missed-3",
+				"task-finally: task-finally is missing the param param-missed-2",
+				"task-finally: task-finally is missing the param param-missed-3",
+			},
 			cTasks: []extendedTask{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "task-a"},
@@ -258,11 +322,225 @@ func TestValidateParams(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "params supplied to another pipelineTask do not count",
+			want: []string{
+				"task-b: task-b is missing the param param1",
+				"task-finally: task-finally is missing the param param2",
+			},
+			cTasks: []extendedTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-a"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param1"},
+							{Name: "param2"},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-finally"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param-finally"},
+							{Name: "param2"},
+						},
+					},
+				},
+			},
+			cClusterTasks: []extendedClusterTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-b"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param3"},
+							{Name: "param1"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "params of a task with the same name as the clusterTask do not count",
+			want: nil,
+			cTasks: []extendedTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-a"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-b"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param-of-task"},
+						},
+					},
+				},
+			},
+			cClusterTasks: []extendedClusterTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-b"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param3"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range validateParamsTests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := tPipeline.ValidateParams(tc.cTasks, tc.cClusterTasks)
+			got := tPipeline.ValidateParams(tc.cTasks, tc.cClusterTasks, nil)
+			assertion(t, got, tc.want)
+		})
+	}
+}
+
+// params that pipelineTasks pass must be declared by the task they refer to
+func TestValidateUndeclaredParams(t *testing.T) {
+	tPipeline := testPipeline(t, yPipeline)
+
+	validateUndeclaredParamsTests := []ValidateParamsTestCases{
+		{
+			name: "pipelineTasks pass declared params only",
+			want: nil,
+			cTasks: []extendedTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-a"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param1"},
+							{Name: "param2"},
+							{Name: "param-extra"},
+							{Name: "param-extra-2"},
+						},
+					},
+				},
+			},
+			cClusterTasks: []extendedClusterTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-b"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param3"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "pipelineTasks pass undeclared params",
+			want: []string{
+				"task-a: task-a passes the param param-extra which is not declared by task-a",
+				"task-a: task-a passes the param param-extra-2 which is not declared by task-a",
+				"task-b: task-b passes the param param3 which is not declared by task-b",
+				"task-finally: task-finally passes the param param-finally which is not declared by task-finally, did you mean param-finaly?",
+			},
+			cTasks: []extendedTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-a"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param1"},
+							{Name: "param2"},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-finally"},
+					Spec: tknv1beta1.TaskSpec{
+						Params: []tknv1beta1.ParamSpec{
+							{Name: "param-finaly"},
+						},
+					},
+				},
+			},
+			cClusterTasks: []extendedClusterTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-b"}},
+			},
+		},
+	}
+
+	for _, tc := range validateUndeclaredParamsTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tPipeline.ValidateUndeclaredParams(tc.cTasks, tc.cClusterTasks, nil)
+			assertion(t, got, tc.want)
+		})
+	}
+}
+
+func TestClosestString(t *testing.T) {
+	candidates := []string{"image", "revision", "url"}
+	for s, want := range map[string]string{
+		"imag":      "image",
+		"revison":   "revision",
+		"urll":      "url",
+		"something": "",
+	} {
+		if got := closestString(s, candidates); got != want {
+			t.Errorf("closest string to %s is %q but wanted %q", s, got, want)
+		}
+	}
+}
+
+// every $(params.x) that pipelineTasks reference must be declared in spec.params
+func TestValidateParamReferences(t *testing.T) {
+	validateParamReferencesTests := []ValidatePipelineTestCases{
+		{
+			name:     "test pipeline declares all the referenced params",
+			pipeline: yPipeline,
+			want:     nil,
+		},
+		{
+			name: "pipeline references undeclared params",
+			want: []string{
+				"task-a: task-a references $(params.param-missing) which is not declared in spec.params",
+				"task-a: task-a references $(params.param-when) which is not declared in spec.params",
+				"task-b: task-b references $(params.param-matrix) which is not declared in spec.params",
+			},
+			pipeline: `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: undeclared-params
+spec:
+  params:
+    - name: param1
+  tasks:
+    - name: task-a
+      taskRef:
+        name: task-a
+      params:
+        - name: param-a
+          value: $(params.param1)-$(params.param-missing)
+        - name: param-b
+          value: $(params["param-missing"])
+      when:
+        - input: $(params.param-when)
+          operator: in
+          values: ["yes"]
+    - name: task-b
+      taskRef:
+        name: task-b
+      matrix:
+        params:
+          - name: platform
+            value: $(params.param-matrix[*])
+    - name: task-c
+      taskSpec:
+        params:
+          - name: own-param
+        steps:
+          - image: ubuntu
+            script: echo $(params.own-param)
+`,
+		},
+	}
+
+	for _, tc := range validateParamReferencesTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tPipeline := testPipeline(t, tc.pipeline)
+			got := tPipeline.ValidateParamReferences()
 			assertion(t, got, tc.want)
 		})
 	}
@@ -272,7 +550,7 @@ func TestValidateParams(t *testing.T) {
 // workspaces that do not have a default value.
 // We ignore optional workspaces
 func TestValidateWorkspaces(t *testing.T) {
-	tPipeline := setupPipeline([]byte(yPipeline))
+	tPipeline := testPipeline(t, yPipeline)
 
 	validateWorkspaceTests := []ValidateWorkspacesTestCases{
 		{
@@ -310,7 +588,12 @@ func TestValidateWorkspaces(t *testing.T) {
 		},
 		{
 			name: "pipeline has a missing workspace",
-			want: errors.New("test-pipeline is missing the following workspaces:\nmap[task-a:[ws-a-missing] task-b:[ws-b-missing ws-b-missing-2] task-finally:[ws-a-missing-finally]]"),
+			want: []string{
+				"task-a: task-a needs the workspace ws-a-missing which is not declared in spec.workspaces",
+				"task-b: task-b needs the workspace ws-b-missing which is not declared in spec.workspaces",
+				"task-b: task-b needs the workspace ws-b-missing-2 which is not declared in spec.workspaces",
+				"task-finally: task-finally needs the workspace ws-a-missing-finally which is not declared in spec.workspaces",
+			},
 			cTasks: []extendedTask{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "task-a"},
@@ -345,26 +628,348 @@ func TestValidateWorkspaces(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "workspaces of a task with the same name as the clusterTask do not count",
+			want: nil,
+			cTasks: []extendedTask{
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-a"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "task-finally"}},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-b"},
+					Spec: tknv1beta1.TaskSpec{
+						Workspaces: []tknv1beta1.WorkspaceDeclaration{
+							{Name: "ws-of-task"},
+						},
+					},
+				},
+			},
+			cClusterTasks: []extendedClusterTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-b"},
+					Spec: tknv1beta1.TaskSpec{
+						Workspaces: []tknv1beta1.WorkspaceDeclaration{
+							{Name: "ws-b-1"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range validateWorkspaceTests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := tPipeline.ValidateWorkspaces(tc.cTasks, tc.cClusterTasks)
+			got := tPipeline.ValidateWorkspaces(tc.cTasks, tc.cClusterTasks, nil)
 			assertion(t, got, tc.want)
 		})
 	}
 }
 
-func assertion(t *testing.T, got, want error) {
-	if got == nil && want != nil {
-		t.Errorf("\nwanted the following error: %s\nbut did not get any error", want)
+// every param and workspace of the pipeline must be used by at least one pipelineTask
+func TestValidateUnusedParams(t *testing.T) {
+	validateUnusedParamsTests := []ValidatePipelineTestCases{
+		{
+			name:     "test pipeline has an unused param",
+			pipeline: yPipeline,
+			want:     []string{"spec.params: the param param-not-needed is not used by any pipelineTask"},
+		},
+		{
+			name: "params are used in when expressions, matrix, inline taskSpec and results",
+			want: nil,
+			pipeline: `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: used-params
+spec:
+  params:
+    - name: param-when
+    - name: param-matrix
+      type: array
+    - name: param-inline
+    - name: param-result
+    - name: param-bracket
+  results:
+    - name: result
+      value: $(params.param-result)
+  tasks:
+    - name: task-a
+      taskRef:
+        name: task-a
+      when:
+        - input: $(params.param-when)
+          operator: in
+          values: ["yes"]
+      matrix:
+        params:
+          - name: platform
+            value: $(params.param-matrix[*])
+    - name: task-b
+      params:
+        - name: bracket
+          value: $(params["param-bracket"])
+      taskSpec:
+        steps:
+          - image: ubuntu
+            script: echo $(params.param-inline)
+`,
+		},
 	}
-	if got != nil {
-		if want == nil {
-			t.Errorf("\ndid not expect error but got the following error: %s", got)
-		}
-		if want.Error() != got.Error() {
-			t.Errorf("\ngot error: %v\nbut wanted: %v", got.Error(), want)
+
+	for _, tc := range validateUnusedParamsTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tPipeline := testPipeline(t, tc.pipeline)
+			got := tPipeline.ValidateUnusedParams()
+			assertion(t, got, tc.want)
+		})
+	}
+}
+
+func TestValidateUnusedWorkspaces(t *testing.T) {
+	validateUnusedWorkspacesTests := []ValidatePipelineTestCases{
+		{
+			name:     "test pipeline has an unused workspace",
+			pipeline: yPipeline,
+			want:     []string{"spec.workspaces: the workspace ws-no-needed is not used by any pipelineTask"},
+		},
+		{
+			name: "workspaces are used by bindings, implicit bindings and variables",
+			want: nil,
+			pipeline: `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: used-workspaces
+spec:
+  workspaces:
+    - name: ws-bound
+    - name: ws-implicit
+    - name: ws-when
+  tasks:
+    - name: task-a
+      taskRef:
+        name: task-a
+      workspaces:
+        - name: source
+          workspace: ws-bound
+        - name: ws-implicit
+      when:
+        - input: $(workspaces.ws-when.bound)
+          operator: in
+          values: ["true"]
+`,
+		},
+	}
+
+	for _, tc := range validateUnusedWorkspacesTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tPipeline := testPipeline(t, tc.pipeline)
+			got := tPipeline.ValidateUnusedWorkspaces()
+			assertion(t, got, tc.want)
+		})
+	}
+}
+
+var yResultsPipeline = `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: results-pipeline
+spec:
+  results:
+    - name: digest
+      value: $(tasks.build.results.digest)
+    - name: report
+      value: $(tasks.notify.results.report)
+  tasks:
+    - name: build
+      taskRef:
+        name: build
+    - name: deploy
+      runAfter:
+        - build
+        - notify
+        - missing
+      taskRef:
+        name: deploy
+      params:
+        - name: image
+          value: $(tasks.build.results.image)@$(tasks.build.results.digest)
+        - name: report
+          value: $(tasks.notify.results.report)
+      when:
+        - input: $(tasks.missing.results.flag)
+          operator: in
+          values: ["true"]
+  finally:
+    - name: notify
+      runAfter:
+        - build
+      taskRef:
+        name: notify
+      params:
+        - name: digest
+          value: $(tasks.build.results["digest"])
+`
+
+// result references must point to pipelineTasks that exist and to results that their tasks declare
+func TestValidateResultRefs(t *testing.T) {
+	tPipeline := testPipeline(t, yResultsPipeline)
+
+	validateResultRefsTests := []ValidateTaskRefsTestCases{
+		{
+			name: "build task declares some of the results",
+			want: []string{
+				"deploy: $(tasks.build.results.image) refers to image result which is not declared by build",
+				"deploy: $(tasks.missing.results.flag) refers to missing which is not a pipelineTask",
+				"deploy: $(tasks.notify.results.report) refers to notify which is a finally task",
+				"spec.results: $(tasks.notify.results.report) refers to report result which is not declared by notify",
+			},
+			cTasks: []extendedTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "build"},
+					Spec: tknv1beta1.TaskSpec{
+						Results: []tknv1beta1.TaskResult{{Name: "digest"}},
+					},
+				},
+				{ObjectMeta: metav1.ObjectMeta{Name: "notify"}},
+			},
+		},
+		{
+			name: "unresolved tasks are not checked for results",
+			want: []string{
+				"deploy: $(tasks.missing.results.flag) refers to missing which is not a pipelineTask",
+				"deploy: $(tasks.notify.results.report) refers to notify which is a finally task",
+			},
+		},
+	}
+
+	for _, tc := range validateResultRefsTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tPipeline.ValidateResultRefs(tc.cTasks, tc.cClusterTasks, nil)
+			assertion(t, got, tc.want)
+		})
+	}
+}
+
+// runAfter must only refer to pipelineTasks in spec.tasks and finally tasks cannot use runAfter
+func TestValidateRunAfter(t *testing.T) {
+	validateRunAfterTests := []ValidatePipelineTestCases{
+		{
+			name:     "test pipeline has valid runAfter",
+			pipeline: yPipeline,
+			want:     nil,
+		},
+		{
+			name:     "pipeline has invalid runAfter",
+			pipeline: yResultsPipeline,
+			want: []string{
+				"deploy: runAfter refers to missing which is not a pipelineTask",
+				"deploy: runAfter refers to notify which is a finally task",
+				"notify: finally tasks cannot use runAfter",
+			},
+		},
+	}
+
+	for _, tc := range validateRunAfterTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tPipeline := testPipeline(t, tc.pipeline)
+			got := tPipeline.ValidateRunAfter()
+			assertion(t, got, tc.want)
+		})
+	}
+}
+
+var yInlinePipeline = `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: inline-pipeline
+spec:
+  workspaces:
+    - name: source
+  tasks:
+    - name: inline-a
+      params:
+        - name: supplied
+          value: some-value
+        - name: extra
+          value: some-value
+      workspaces:
+        - name: output
+          workspace: source
+      taskSpec:
+        params:
+          - name: supplied
+          - name: missing
+          - name: defaulted
+            default: default-value
+        workspaces:
+          - name: output
+          - name: cache
+          - name: optional
+            optional: true
+        results:
+          - name: digest
+        steps:
+          - image: ubuntu
+            script: echo 'hello there'
+    - name: inline-b
+      params:
+        - name: digest
+          value: $(tasks.inline-a.results.digest)
+        - name: image
+          value: $(tasks.inline-a.results.image)
+      taskSpec:
+        params:
+          - name: digest
+          - name: image
+        steps:
+          - image: ubuntu
+            script: echo 'hello there'
+`
+
+// inline taskSpecs must be validated the same way as the tasks that are referred by taskRef
+func TestValidateInlineTasks(t *testing.T) {
+	tPipeline := testPipeline(t, yInlinePipeline)
+
+	assertion(t, tPipeline.ValidateParams(nil, nil, nil),
+		[]string{"inline-a: inline-a is missing the param missing"})
+	assertion(t, tPipeline.ValidateWorkspaces(nil, nil, nil),
+		[]string{"inline-a: inline-a needs the workspace cache which is not declared in spec.workspaces"})
+	assertion(t, tPipeline.ValidateUndeclaredParams(nil, nil, nil),
+		[]string{"inline-a: inline-a passes the param extra which is not declared by inline-a"})
+	assertion(t, tPipeline.ValidateResultRefs(nil, nil, nil),
+		[]string{"inline-b: $(tasks.inline-a.results.image) refers to image result which is not declared by inline-a"})
+}
+
+// Converts the yaml into a typed pipeline and fails the test if it is not a valid pipeline
+func testPipeline(t *testing.T, y string) extendedPipeline {
+	t.Helper()
+	uPipeline, err := decodeDocument([]byte(y))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tPipeline, err := toPipeline(uPipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tPipeline
+}
+
+// Compares the diagnostics, in the order that they are returned, with the wanted pipelineTask (or field): message lines
+func assertion(t *testing.T, got []diagnostic, want []string) {
+	t.Helper()
+	var gotLines []string
+	for _, d := range got {
+		gotLines = append(gotLines, d.String())
+	}
+	if len(gotLines) != len(want) {
+		t.Errorf("\ngot diagnostics:\n%s\nbut wanted:\n%s", strings.Join(gotLines, "\n"), strings.Join(want, "\n"))
+		return
+	}
+	for i := range want {
+		if gotLines[i] != want[i] {
+			t.Errorf("\ngot diagnostic: %s\nbut wanted: %s", gotLines[i], want[i])
 		}
 	}
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	tknv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	tknv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"knative.dev/pkg/apis"
+)
+
+const tektonGroup = "tekton.dev"
+
+// Versions of tekton.dev that mario can read, in order of preference. v1beta1 is the version-neutral model
+// that the validations run on, objects of the other versions are converted to it.
+var tektonVersions = []string{"v1", "v1beta1"}
+
+// Versions that are used to read the tekton.dev resources from the cluster, keyed by resource.
+// Resources that are not in the map are read as v1beta1.
+var servedVersions = map[string]string{}
+
+// Returns the GroupVersionResource that is used to read the given tekton.dev resource from the cluster
+func tektonResource(resource string) schema.GroupVersionResource {
+	version, ok := servedVersions[resource]
+	if !ok {
+		version = "v1beta1"
+	}
+	return schema.GroupVersionResource{Group: tektonGroup, Version: version, Resource: resource}
+}
+
+// Asks the cluster which versions of tekton.dev it serves and returns the preferred version of every resource.
+// Versions that the cluster does not serve are skipped.
+func discoverVersions(d discovery.DiscoveryInterface) (map[string]string, error) {
+	versions := make(map[string]string)
+	for _, version := range tektonVersions {
+		resources, err := d.ServerResourcesForGroupVersion(tektonGroup + "/" + version)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("discovering the resources of %s/%s: %w", tektonGroup, version, err)
+		}
+		for _, r := range resources.APIResources {
+			if _, ok := versions[r.Name]; !ok {
+				versions[r.Name] = version
+			}
+		}
+	}
+	return versions, nil
+}
+
+// Object which can be converted from another version of itself
+type convertibleFrom interface {
+	ConvertFrom(ctx context.Context, from apis.Convertible) error
+	SetGroupVersionKind(gvk schema.GroupVersionKind)
+}
+
+// Converts the unstructured object into the model. tekton.dev/v1 objects are decoded into the given v1 object
+// and converted with the tekton conversions, other objects are decoded into the model as they are. The
+// apiVersion and kind of the object are kept so that the diagnostics name the version that was read.
+func convertObject(u *unstructured.Unstructured, model convertibleFrom, v1Object apis.Convertible) error {
+	if u.GetAPIVersion() != tknv1.SchemeGroupVersion.String() {
+		return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, model)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, v1Object); err != nil {
+		return err
+	}
+	if err := model.ConvertFrom(context.Background(), v1Object); err != nil {
+		return err
+	}
+	model.SetGroupVersionKind(u.GroupVersionKind())
+	return nil
+}
+
+// Converts an unstructured pipeline of any supported version into the model
+func toPipeline(u *unstructured.Unstructured) (eP extendedPipeline, err error) {
+	err = convertObject(u, (*tknv1beta1.Pipeline)(&eP), &tknv1.Pipeline{})
+	return
+}
+
+// Converts an unstructured task of any supported version into the model
+func toTask(u *unstructured.Unstructured) (eT extendedTask, err error) {
+	err = convertObject(u, (*tknv1beta1.Task)(&eT), &tknv1.Task{})
+	return
+}
+
+// Converts an unstructured pipelineRun of any supported version into the model
+func toPipelineRun(u *unstructured.Unstructured) (ePR extendedPipelineRun, err error) {
+	err = convertObject(u, (*tknv1beta1.PipelineRun)(&ePR), &tknv1.PipelineRun{})
+	return
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Returns the yaml with every tekton.dev/v1beta1 object turned into a tekton.dev/v1 object
+func toV1(y string) string {
+	return strings.ReplaceAll(y, "apiVersion: tekton.dev/v1beta1", "apiVersion: tekton.dev/v1")
+}
+
+func TestDiscoverVersions(t *testing.T) {
+	resources := func(groupVersion string, names ...string) *metav1.APIResourceList {
+		list := &metav1.APIResourceList{GroupVersion: groupVersion}
+		for _, name := range names {
+			list.APIResources = append(list.APIResources, metav1.APIResource{Name: name})
+		}
+		return list
+	}
+	testCases := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		want      map[string]string
+	}{
+		{
+			name:      "v1beta1 only",
+			resources: []*metav1.APIResourceList{resources("tekton.dev/v1beta1", "pipelines", "tasks", "clustertasks")},
+			want:      map[string]string{"pipelines": "v1beta1", "tasks": "v1beta1", "clustertasks": "v1beta1"},
+		},
+		{
+			name: "v1 is preferred",
+			resources: []*metav1.APIResourceList{
+				resources("tekton.dev/v1beta1", "pipelines", "tasks", "clustertasks"),
+				resources("tekton.dev/v1", "pipelines", "tasks"),
+			},
+			want: map[string]string{"pipelines": "v1", "tasks": "v1", "clustertasks": "v1beta1"},
+		},
+		{
+			name: "tekton is not installed",
+			want: map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{Resources: tc.resources}}
+			got, err := discoverVersions(d)
+			if err != nil {
+				t.Fatalf("did not expect error but got: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v but wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// v1 objects must get the same diagnostics as their v1beta1 counterparts
+func TestV1Validations(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "v1beta1", "tasks.yaml"), yTasks)
+	writeFile(t, filepath.Join(dir, "v1", "tasks.yaml"), toV1(yTasks))
+	v1beta1Tasks, v1beta1ClusterTasks, _, err := loadTasks([]string{filepath.Join(dir, "v1beta1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Tasks, v1ClusterTasks, invalid, err := loadTasks([]string{filepath.Join(dir, "v1")})
+	if err != nil || len(invalid) != 0 {
+		t.Fatalf("did not expect errors but got %v and %v", err, invalid)
+	}
+	if len(v1Tasks) != 1 || v1Tasks[0].APIVersion != "tekton.dev/v1" || v1Tasks[0].Kind != "Task" {
+		t.Fatalf("got %v but wanted task-a as a tekton.dev/v1 Task", v1Tasks)
+	}
+
+	for name, y := range map[string]string{
+		"pipeline":         yPipeline,
+		"results pipeline": yResultsPipeline,
+		"inline pipeline":  yInlinePipeline,
+		"typed pipeline":   yTypedPipeline,
+	} {
+		t.Run(name, func(t *testing.T) {
+			v1beta1Pipeline, v1Pipeline := testPipeline(t, y), testPipeline(t, toV1(y))
+			if v1Pipeline.APIVersion != "tekton.dev/v1" {
+				t.Errorf("got %s but wanted the apiVersion of the manifest", v1Pipeline.APIVersion)
+			}
+			want := runValidations(&v1beta1Pipeline, v1beta1Tasks, v1beta1ClusterTasks, nil)
+			got := runValidations(&v1Pipeline, v1Tasks, v1ClusterTasks, nil)
+			var wantLines []string
+			for _, d := range want {
+				wantLines = append(wantLines, d.String())
+			}
+			assertion(t, got, wantLines)
+		})
+	}
+}
+
+// the pipelines and tasks must be read with the versions that the cluster serves
+func TestValidateClusterV1(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "tekton.dev", Version: "v1", Resource: "pipelines"}:         "PipelineList",
+		{Group: "tekton.dev", Version: "v1", Resource: "tasks"}:             "TaskList",
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "clustertasks"}: "ClusterTaskList",
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		testObject(t, toV1(yPipeline), "team-a"),
+		testObject(t, toV1(yTaskFinally), "team-a"),
+	)
+
+	defer func(files []string, versions map[string]string) { pipelineFiles, servedVersions = files, versions }(pipelineFiles, servedVersions)
+	pipelineFiles = nil
+	servedVersions = map[string]string{"pipelines": "v1", "tasks": "v1", "clustertasks": "v1beta1"}
+
+	var out strings.Builder
+	err := validateCluster(&out, client, manifestSet{}, "team-a")
+	if got := exitCode(err); got != exitFindings {
+		t.Errorf("got exit code %d (%v) but wanted %d", got, err, exitFindings)
+	}
+	if !strings.Contains(out.String(), "team-a/test-pipeline: error: task-a refers to the task task-a which is not found [task-ref]") {
+		t.Errorf("wanted the v1 pipeline to be validated but got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "task-finally which does not exist") || strings.Contains(out.String(), "[unreadable]") {
+		t.Errorf("wanted the v1 task-finally to be found but got:\n%s", out.String())
+	}
+}